@@ -1,49 +1,425 @@
 package main
 
 import (
+	"bytes"
+	"compiler/jack"
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"strings"
+	"path/filepath"
+	"strconv"
 )
 
+// Command line options
+type options struct {
+	jack.Options
+	diagnostics string // one of the DIAGNOSTICS_ formats
+	paths       bool   // name the files by their path instead of their base name in the diagnostics
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("No provided file or directory")
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		formatMain(os.Args[2:])
+		return
+	}
+	opts := options{}
+	flag.BoolVar(&opts.Tokens, "tokens", false, "write the tokens of each .jack file to XxxT.xml instead of compiling it")
+	flag.BoolVar(&opts.XML, "xml", false, "also write the parse tree of each .jack file to Xxx.xml")
+	flag.BoolVar(&opts.Escapes, "escapes", false, "recognize the escape sequences \\\" \\\\ \\n \\b and \\xNN in string constants")
+	flag.StringVar(&opts.Charset, "charset", jack.CHARSET_WARN, "handling of characters outside the Hack character set in string constants: error, warn or transliterate")
+	flag.StringVar(&opts.diagnostics, "diagnostics", jack.DIAGNOSTICS_TEXT, "format of the errors and warnings written to stderr: text, json or sarif")
+	flag.BoolVar(&opts.StrictTypes, "strict", false, "report type mismatches as errors instead of warnings")
+	flag.IntVar(&opts.Jobs, "j", 1, "number of files compiled at the same time")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: JackAnalyzer [options] file.jack|directory ...\n       JackAnalyzer [options] -\n       JackAnalyzer fmt [options] file.jack|directory|- ...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if opts.Jobs < 1 {
+		fmt.Fprintln(os.Stderr, "-j must be at least 1")
+		os.Exit(1)
+	}
+	if !jack.IsCharsetPolicy(opts.Charset) {
+		fmt.Fprintln(os.Stderr, "Unknown -charset policy "+opts.Charset)
+		os.Exit(1)
+	}
+	if !jack.IsDiagnosticsFormat(opts.diagnostics) {
+		fmt.Fprintln(os.Stderr, "Unknown -diagnostics format "+opts.diagnostics)
+		os.Exit(1)
+	}
+	if flag.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "No provided file or directory")
 		os.Exit(1)
 	}
 
-	fileOrDir := os.Args[1]
+	dw := jack.CreateDiagnosticWriter(os.Stderr, opts.diagnostics)
+	if flag.Arg(0) == "-" { // read a single class from the standard input
+		diagnostics, source := compileStdin(opts)
+		dw.Write(diagnostics, source)
+		dw.Close()
+		exitOnErrors(opts, countErrors(diagnostics), 1)
+		return
+	}
+
+	programs, jackFiles := collectPrograms(flag.Args(), opts)
+	opts.paths = flag.NArg() > 1
+
+	// for each .jack file we generate its .vm output file (and its .xml parse tree if requested), or its T.xml file in tokens mode.
+	// The files of each program are compiled by opts.Jobs workers, the diagnostics are written in the order of the files.
+	errorCount := 0
+	failedFiles := 0
+	for _, result := range compilePrograms(programs, len(jackFiles), opts) {
+		<-result.done
+		if result.err != nil {
+			fmt.Fprintln(os.Stderr, result.err)
+			errorCount++
+			failedFiles++
+			continue
+		}
+		dw.Write(result.diagnostics, result.source)
+		errorCount += countErrors(result.diagnostics)
+		if countErrors(result.diagnostics) > 0 {
+			failedFiles++
+		}
+	}
+	dw.Close()
+	exitOnErrors(opts, errorCount, failedFiles)
+}
+
+// The .jack files of a directory, which are compiled together so that the calls between them can be checked
+type program struct {
+	jackFiles []string
+	compiled  map[string]int // the files to compile, the others are only read for their declarations, mapped to their index in the results
+}
+
+// Groups the input files by directory into programs. A directory compiles all its files, a file is compiled with
+// the declarations of the others of its directory. Also returns the files to compile, in the order of the results.
+func collectPrograms(inputs []string, opts options) ([]*program, []string) {
+	programs := make([]*program, 0)
+	byDir := make(map[string]*program) // a file given twice would be compiled and written twice
+	jackFiles := make([]string, 0)
+	for _, fileOrDir := range inputs {
+		compiled := collectJackFiles(fileOrDir)
+		if len(compiled) == 0 {
+			continue
+		}
+		dir := filepath.Dir(compiled[0])
+		prog := byDir[dir]
+		if prog == nil {
+			prog = &program{jackFiles: compiled, compiled: make(map[string]int)}
+			if !opts.Tokens && len(compiled) == 1 && compiled[0] == filepath.Clean(fileOrDir) { // a single file
+				prog.jackFiles = collectJackFiles(dir)
+			}
+			byDir[dir] = prog
+			programs = append(programs, prog)
+		}
+		for _, jackFile := range compiled {
+			if _, ok := prog.compiled[jackFile]; !ok {
+				prog.compiled[jackFile] = len(jackFiles)
+				jackFiles = append(jackFiles, jackFile)
+			}
+		}
+	}
+	return programs, jackFiles
+}
 
+// The outcome of the compilation of a file, available once done is closed
+type fileResult struct {
+	diagnostics []jack.Diagnostic
+	source      []byte // the content of the file, for rendering the diagnostics, see readSource
+	err         error  // the file couldn't be read or its output files couldn't be written
+	done        chan struct{}
+}
+
+// Starts compiling the programs with a pool of workers, and returns the results of their fileCount files.
+// The opts.Jobs files compiled at the same time are shared between the workers and the programs they compile:
+// a single program has opts.Jobs files compiled at the same time, many programs are compiled one file at a time.
+func compilePrograms(programs []*program, fileCount int, opts options) []*fileResult {
+	results := make([]*fileResult, fileCount)
+	for i := range results {
+		results[i] = &fileResult{done: make(chan struct{})}
+	}
+	workers := opts.Jobs
+	if len(programs) < workers {
+		workers = len(programs)
+	}
+	if workers > 0 {
+		opts.Jobs /= workers
+	}
+	jobs := make(chan *program)
+	go func() {
+		for _, prog := range programs {
+			jobs <- prog
+		}
+		close(jobs)
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for prog := range jobs {
+				compileProgram(prog, results, opts)
+			}
+		}()
+	}
+	return results
+}
+
+// Returns the .jack files of a directory, or the file itself
+func collectJackFiles(fileOrDir string) []string {
 	// This returns an *os.FileInfo type
 	info, err := os.Stat(fileOrDir)
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
+	jackFiles := make([]string, 0)
 	if info.IsDir() { // is directory
 
 		files, err := os.ReadDir(fileOrDir)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
 
-		// for each .jack file we generate its .xml output file
 		for _, file := range files {
-			fileStructure := strings.Split(file.Name(), ".")
-			if fileStructure[1] == "jack" {
-				input, _ := os.Open(fileOrDir + "/" + file.Name())
-				output, _ := os.Create(fileOrDir + "/" + fileStructure[0] + "1.vm")
-				cEngine := CreateCompilationEngine(input, output)
-				cEngine.CompileClass()
+			if filepath.Ext(file.Name()) == ".jack" {
+				jackFiles = append(jackFiles, filepath.Join(fileOrDir, file.Name()))
 			}
 		}
 
 	} else { // is file
-		input, _ := os.Open(fileOrDir)
-		output, _ := os.Create(strings.Split(fileOrDir, ".")[0] + "1.vm")
-		cEngine := CreateCompilationEngine(input, output)
-		cEngine.CompileClass()
+		jackFiles = append(jackFiles, filepath.Clean(fileOrDir))
+	}
+	return jackFiles
+}
+
+// Exits with status 1 if errors were reported for the input files,
+// after a summary of them in the text diagnostics format
+func exitOnErrors(opts options, errorCount int, failedFiles int) {
+	if errorCount == 0 {
+		return
+	}
+	if opts.diagnostics == jack.DIAGNOSTICS_TEXT {
+		summary := plural(errorCount, "error")
+		if failedFiles > 1 {
+			summary += " in " + plural(failedFiles, "file")
+		}
+		fmt.Fprintln(os.Stderr, summary)
+	}
+	os.Exit(1)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+func countErrors(diagnostics []jack.Diagnostic) int {
+	count := 0
+	for _, d := range diagnostics {
+		if d.Severity == jack.SEVERITY_ERROR {
+			count++
+		}
+	}
+	return count
+}
+
+// Compiles the files of a program, or writes their tokens in tokens mode, and sets their results.
+// A file that can't be opened fails alone, the other files of the program are still compiled.
+func compileProgram(prog *program, results []*fileResult, opts options) {
+	sources := make(map[string]io.Reader)
+	inputs := make(map[string]*lazyFile)
+	compileOpts := opts.Options
+	compileOpts.Declarations = nil
+	for _, jackFile := range prog.jackFiles {
+		input := &lazyFile{path: jackFile}
+		defer input.Close()
+		inputs[jackFile] = input
+		sources[diagnosticsName(jackFile, opts)] = input
+		if _, ok := prog.compiled[jackFile]; !ok {
+			compileOpts.Declarations = append(compileOpts.Declarations, diagnosticsName(jackFile, opts))
+		}
+	}
+	outputs, diagnostics, _ := jack.Compile(context.Background(), sources, compileOpts)
+
+	for jackFile, i := range prog.compiled {
+		result := results[i]
+		if err := inputs[jackFile].openErr; err != nil {
+			result.err = err
+			close(result.done)
+			continue
+		}
+		name := diagnosticsName(jackFile, opts)
+		for _, d := range diagnostics {
+			if d.File == name {
+				result.diagnostics = append(result.diagnostics, d)
+			}
+		}
+		result.err = writeOutputs(jackFile, outputs, opts)
+		if result.err == nil {
+			result.source = readSource(jackFile, result.diagnostics, opts)
+		}
+		close(result.done)
+	}
+}
+
+// A file opened when it is first read and closed once it is read to the end,
+// so that only the files being compiled are open
+type lazyFile struct {
+	path    string
+	file    *os.File
+	openErr error // the file couldn't be opened
+	readErr error // the error that ended the reading, io.EOF at the end of the file
+}
+
+func (lf *lazyFile) Read(p []byte) (int, error) {
+	if lf.file == nil && lf.openErr == nil && lf.readErr == nil {
+		lf.file, lf.openErr = os.Open(lf.path)
+	}
+	if lf.openErr != nil {
+		return 0, lf.openErr
+	}
+	if lf.readErr != nil {
+		return 0, lf.readErr
+	}
+	n, err := lf.file.Read(p)
+	if err != nil {
+		lf.readErr = err
+		lf.Close()
+	}
+	return n, err
+}
+
+// Closes the file if it is still open, when it wasn't read to the end
+func (lf *lazyFile) Close() error {
+	if lf.file == nil {
+		return nil
+	}
+	err := lf.file.Close()
+	lf.file = nil
+	return err
+}
+
+// Writes the outputs of the .jack file next to it
+func writeOutputs(jackFile string, outputs map[string][]byte, opts options) error {
+	// the .vm file is written even without code, so that the one of a previous compilation doesn't stay
+	suffixes := []string{jack.VM_SUFFIX}
+	if opts.Tokens {
+		suffixes = []string{jack.TOKENS_SUFFIX}
+	} else if opts.XML {
+		suffixes = append(suffixes, jack.XML_SUFFIX)
+	}
+	name := diagnosticsName(jackFile, opts)
+	for _, suffix := range suffixes {
+		if err := os.WriteFile(jack.OutputName(jackFile, suffix), outputs[jack.OutputName(name, suffix)], 0666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compiles the class read from the standard input and writes the result to the standard output,
+// the input is also returned for rendering the diagnostics
+func compileStdin(opts options) ([]jack.Diagnostic, []byte) {
+	source := &bytes.Buffer{}
+	input := io.TeeReader(os.Stdin, source)
+	compileOpts := opts.Options
+	compileOpts.XML = false
+	compileOpts.Incomplete = true // the other classes are unknown
+	outputs, diagnostics, _ := jack.Compile(context.Background(), map[string]io.Reader{"stdin": input}, compileOpts)
+	if opts.Tokens {
+		os.Stdout.Write(outputs[jack.OutputName("stdin", jack.TOKENS_SUFFIX)])
+	} else {
+		os.Stdout.Write(outputs[jack.OutputName("stdin", jack.VM_SUFFIX)])
+	}
+	return diagnostics, source.Bytes()
+}
+
+// Returns the name of the .jack file in the diagnostics: its base name, or its path when
+// several inputs are compiled, as they may have files with the same name
+func diagnosticsName(jackFile string, opts options) string {
+	if opts.paths {
+		return filepath.ToSlash(jackFile)
+	}
+	return filepath.Base(jackFile)
+}
+
+// Returns the content of the .jack file if its diagnostics are rendered with their source lines, nil otherwise
+func readSource(jackFile string, diagnostics []jack.Diagnostic, opts options) []byte {
+	if opts.diagnostics != jack.DIAGNOSTICS_TEXT || len(diagnostics) == 0 {
+		return nil
+	}
+	source, err := os.ReadFile(jackFile)
+	if err != nil {
+		return nil
+	}
+	return source
+}
+
+// The fmt command, rewrites .jack files in the canonical style of the jack.JackFormatter
+func formatMain(args []string) {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	check := flags.Bool("check", false, "don't rewrite the files, list the ones that are not formatted and exit with status 1 if there are any")
+	split := flags.Bool("one-per-line", false, "split declarations of several variables into one declaration per variable")
+	escapes := flags.Bool("escapes", false, "recognize the escape sequences \\\" \\\\ \\n \\b and \\xNN in string constants")
+	flags.Parse(args)
+	if flags.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "No provided file or directory")
+		os.Exit(1)
+	}
+
+	dw := jack.CreateDiagnosticWriter(os.Stderr, jack.DIAGNOSTICS_TEXT)
+	success := true
+	for _, fileOrDir := range flags.Args() {
+		if fileOrDir == "-" { // format the standard input to the standard output
+			source, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			reporter := jack.CreateErrorReporter()
+			formatted := jack.FormatSource("stdin", source, *escapes, *split, reporter)
+			dw.Write(reporter.Diagnostics(), source)
+			if formatted == nil || (*check && !bytes.Equal(formatted, source)) {
+				success = false
+			}
+			if formatted != nil && !*check {
+				os.Stdout.Write(formatted)
+			}
+			continue
+		}
+		for _, jackFile := range collectJackFiles(fileOrDir) {
+			source, err := os.ReadFile(jackFile)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			reporter := jack.CreateErrorReporter()
+			formatted := jack.FormatSource(filepath.Base(jackFile), source, *escapes, *split, reporter)
+			dw.Write(reporter.Diagnostics(), source)
+			if formatted == nil {
+				success = false
+				continue
+			}
+			if bytes.Equal(formatted, source) {
+				continue
+			}
+			if *check {
+				fmt.Println(jackFile)
+				success = false
+				continue
+			}
+			if err := os.WriteFile(jackFile, formatted, 0644); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+	}
+	if !success {
+		os.Exit(1)
 	}
 }
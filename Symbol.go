@@ -1,8 +0,0 @@
-package main
-
-type Symbol struct {
-	name  string
-	sType string
-	kind  string
-	index int
-}
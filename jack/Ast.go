@@ -0,0 +1,259 @@
+package jack
+
+// The abstract syntax tree built by the CompilationEngine and walked by the CodeGenerator.
+// Every node records the position of its first token, binary expressions record the position of their operator.
+
+type Node interface {
+	Position() Position
+}
+
+type Statement interface {
+	Node
+	statementNode()
+}
+
+type Expression interface {
+	Node
+	EndPosition() Position // just after the last character of the expression
+	expressionNode()
+}
+
+// A name together with the position it appears at
+type Ident struct {
+	Pos  Position
+	Name string
+}
+
+type Class struct {
+	Pos         Position
+	Name        string
+	NamePos     Position
+	VarDecs     []*ClassVarDec
+	Subroutines []*Subroutine
+}
+
+// static or field declaration of one or more variables of the same type
+type ClassVarDec struct {
+	Pos     Position
+	Kind    string // STATIC or FIELD
+	Type    string
+	TypePos Position
+	Names   []Ident
+}
+
+type Subroutine struct {
+	Pos           Position
+	Kind          string // "constructor", "function" or "method"
+	ReturnType    string
+	ReturnTypePos Position
+	Name          string
+	NamePos       Position
+	Params        []*Parameter
+	Locals        []*VarDec
+	Body          []Statement
+}
+
+type Parameter struct {
+	Pos     Position // position of the type
+	Type    string
+	Name    string
+	NamePos Position
+}
+
+// var declaration of one or more local variables of the same type
+type VarDec struct {
+	Pos     Position
+	Type    string
+	TypePos Position
+	Names   []Ident
+}
+
+// Statements
+
+type LetStmt struct {
+	Pos     Position
+	Name    string
+	NamePos Position
+	Index   Expression // nil unless assigning to an array element
+	Value   Expression
+}
+
+type IfStmt struct {
+	Pos     Position
+	Cond    Expression
+	Then    []Statement
+	HasElse bool
+	Else    []Statement
+}
+
+type WhileStmt struct {
+	Pos  Position
+	Cond Expression
+	Body []Statement
+}
+
+type DoStmt struct {
+	Pos  Position
+	Call *CallExpr
+}
+
+type ReturnStmt struct {
+	Pos   Position
+	Value Expression // nil for "return;"
+}
+
+// Expressions
+
+type IntLiteral struct {
+	Pos   Position
+	End   Position
+	Value int
+}
+
+type StringLiteral struct {
+	Pos   Position
+	End   Position
+	Value string
+}
+
+// Character literal such as 'A', Value is its Hack character code
+type CharLiteral struct {
+	Pos   Position
+	End   Position
+	Value int
+	Text  string
+}
+
+// true, false, null or this
+type KeywordLiteral struct {
+	Pos     Position
+	End     Position
+	Keyword string
+}
+
+type VarRef struct {
+	Pos  Position
+	End  Position
+	Name string
+}
+
+// varName[Index]
+type IndexExpr struct {
+	Pos   Position
+	End   Position
+	Name  string
+	Index Expression
+}
+
+// Subroutine call, Receiver is the class or variable name before the dot, "" for name(...)
+type CallExpr struct {
+	Pos      Position
+	End      Position
+	Receiver string
+	Name     string
+	NamePos  Position
+	Args     []Expression
+}
+
+// - or ~ applied to a term
+type UnaryExpr struct {
+	Pos     Position
+	End     Position
+	Op      string
+	Operand Expression
+}
+
+// Jack has no operator precedence, so a chain of operators becomes a left-leaning tree.
+// Pos is the position of the operator, the expression starts with Left.
+type BinaryExpr struct {
+	Pos   Position
+	End   Position
+	Op    string
+	Left  Expression
+	Right Expression
+}
+
+// (expression)
+type ParenExpr struct {
+	Pos   Position
+	End   Position
+	Inner Expression
+}
+
+// Returns the subroutine of the class with the given name, nil if there is none
+func (n *Class) Subroutine(name string) *Subroutine {
+	for _, sub := range n.Subroutines {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Returns the range of an expression, from its first character to its last one
+func exprRange(e Expression) Range {
+	first := e
+	for binary, ok := first.(*BinaryExpr); ok; binary, ok = first.(*BinaryExpr) {
+		first = binary.Left
+	}
+	return Range{Start: first.Position(), End: e.EndPosition()}
+}
+
+// Returns the range of a name that starts at pos
+func nameRange(pos Position, name string) Range {
+	end := pos
+	end.Column += len(name)
+	end.Offset += len(name)
+	return Range{Start: pos, End: end}
+}
+
+func (n *Class) Position() Position       { return n.Pos }
+func (n *ClassVarDec) Position() Position { return n.Pos }
+func (n *Subroutine) Position() Position  { return n.Pos }
+func (n *Parameter) Position() Position   { return n.Pos }
+func (n *VarDec) Position() Position      { return n.Pos }
+
+func (s *LetStmt) Position() Position    { return s.Pos }
+func (s *IfStmt) Position() Position     { return s.Pos }
+func (s *WhileStmt) Position() Position  { return s.Pos }
+func (s *DoStmt) Position() Position     { return s.Pos }
+func (s *ReturnStmt) Position() Position { return s.Pos }
+
+func (s *LetStmt) statementNode()    {}
+func (s *IfStmt) statementNode()     {}
+func (s *WhileStmt) statementNode()  {}
+func (s *DoStmt) statementNode()     {}
+func (s *ReturnStmt) statementNode() {}
+
+func (e *IntLiteral) Position() Position     { return e.Pos }
+func (e *StringLiteral) Position() Position  { return e.Pos }
+func (e *CharLiteral) Position() Position    { return e.Pos }
+func (e *KeywordLiteral) Position() Position { return e.Pos }
+func (e *VarRef) Position() Position         { return e.Pos }
+func (e *IndexExpr) Position() Position      { return e.Pos }
+func (e *CallExpr) Position() Position       { return e.Pos }
+func (e *UnaryExpr) Position() Position      { return e.Pos }
+func (e *BinaryExpr) Position() Position     { return e.Pos }
+func (e *ParenExpr) Position() Position      { return e.Pos }
+
+func (e *IntLiteral) EndPosition() Position     { return e.End }
+func (e *StringLiteral) EndPosition() Position  { return e.End }
+func (e *CharLiteral) EndPosition() Position    { return e.End }
+func (e *KeywordLiteral) EndPosition() Position { return e.End }
+func (e *VarRef) EndPosition() Position         { return e.End }
+func (e *IndexExpr) EndPosition() Position      { return e.End }
+func (e *CallExpr) EndPosition() Position       { return e.End }
+func (e *UnaryExpr) EndPosition() Position      { return e.End }
+func (e *BinaryExpr) EndPosition() Position     { return e.End }
+func (e *ParenExpr) EndPosition() Position      { return e.End }
+
+func (e *IntLiteral) expressionNode()     {}
+func (e *StringLiteral) expressionNode()  {}
+func (e *CharLiteral) expressionNode()    {}
+func (e *KeywordLiteral) expressionNode() {}
+func (e *VarRef) expressionNode()         {}
+func (e *IndexExpr) expressionNode()      {}
+func (e *CallExpr) expressionNode()       {}
+func (e *UnaryExpr) expressionNode()      {}
+func (e *BinaryExpr) expressionNode()     {}
+func (e *ParenExpr) expressionNode()      {}
@@ -0,0 +1,71 @@
+package jack_test
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"compiler/jack"
+)
+
+// Returns a class with n subroutines using every kind of token and statement
+func largeClass(name string, n int) string {
+	var sb strings.Builder
+	sb.WriteString("/** A generated class */\nclass " + name + " {\n\tfield int x, y;\n\tstatic Array cache;\n\n")
+	for i := 0; i < n; i++ {
+		sb.WriteString("\t// computes something\n\tmethod int compute" + strconv.Itoa(i) + "(int a, char c) {\n")
+		sb.WriteString("\t\tvar int i, sum;\n\t\tvar String s;\n")
+		sb.WriteString("\t\tlet s = \"iteration " + strconv.Itoa(i) + "\";\n")
+		sb.WriteString("\t\tlet i = 0;\n\t\tlet sum = 0x10 + 0b101;\n")
+		sb.WriteString("\t\twhile ((i < a) & ~(sum > 1000)) {\n")
+		sb.WriteString("\t\t\tif (cache[i] = null) { let sum = sum + (i * 2); }\n")
+		sb.WriteString("\t\t\telse { let sum = sum - Math.abs(-x); }\n")
+		sb.WriteString("\t\t\tlet i = i + 1;\n\t\t}\n")
+		sb.WriteString("\t\tdo Output.printString(s);\n\t\tdo Output.printInt(sum / (y + 1));\n")
+		sb.WriteString("\t\treturn sum + c;\n\t}\n\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func BenchmarkTokenizer(b *testing.B) {
+	source := largeClass("Main", 500)
+	b.SetBytes(int64(len(source)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// the streaming API, Tokenize would measure the growth of its slice of tokens
+		jt := jack.CreateTokenizerFromReader("Main.jack", strings.NewReader(source))
+		for jt.HasMoreTokens() {
+			_ = jt.CurrentToken()
+			jt.Advance()
+		}
+		if errors := jt.Errors(); len(errors) != 0 {
+			b.Fatal(errors)
+		}
+	}
+}
+
+func BenchmarkCompile(b *testing.B) {
+	sources := make(map[string]string)
+	size := 0
+	for _, name := range []string{"Main", "Game", "Board", "Player"} {
+		sources[name+".jack"] = largeClass(name, 500)
+		size += len(sources[name+".jack"])
+	}
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		readers := make(map[string]io.Reader, len(sources))
+		for name, source := range sources {
+			readers[name] = strings.NewReader(source)
+		}
+		_, diagnostics, err := jack.Compile(context.Background(), readers, jack.Options{})
+		if err != nil || len(diagnostics) != 0 {
+			b.Fatal(err, diagnostics)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package jack
+
+import "sort"
+
+// The declarations of the classes of a program and of the Jack OS, so that the uses of a class
+// in the other files of the program can be checked. A class of the program replaces the OS class of the same name.
+type ClassIndex struct {
+	classes  map[string]*Class
+	partial  map[string]bool // classes with syntax errors, whose declarations may be missing
+	complete bool            // every class of the program is in the index
+}
+
+// Creates an index of the OS classes. The classes of the program are added with Add, complete tells whether they
+// are all going to be added: if not, names that are not in the index may be classes of the program.
+func CreateClassIndex(complete bool) *ClassIndex {
+	ci := &ClassIndex{classes: make(map[string]*Class), partial: make(map[string]bool), complete: complete}
+	for _, class := range OSClasses() {
+		ci.classes[class.Name] = class
+	}
+	return ci
+}
+
+// Adds a class of the program, partial tells whether it had syntax errors.
+// If the program already has a class of the same name, it is kept and returned with ok false.
+func (ci *ClassIndex) Add(class *Class, partial bool) (first *Class, ok bool) {
+	if class.Name == "" {
+		return nil, true
+	}
+	if first, found := ci.classes[class.Name]; found && first.Pos.File != OS_FILE {
+		return first, false
+	}
+	ci.classes[class.Name] = class
+	ci.partial[class.Name] = partial
+	return class, true
+}
+
+// Returns the class of the given name, nil if it isn't in the index
+func (ci *ClassIndex) Class(name string) *Class {
+	return ci.classes[name]
+}
+
+// Tells whether name is a class, or may be one when the index isn't complete
+func (ci *ClassIndex) MayBeClass(name string) bool {
+	return ci.classes[name] != nil || !ci.complete
+}
+
+// Returns the subroutine of a class, nil if the class or the subroutine isn't in the index.
+// ok is false only if the subroutine is known not to exist, because the class is in the index without errors.
+func (ci *ClassIndex) Subroutine(className string, name string) (sub *Subroutine, ok bool) {
+	class := ci.classes[className]
+	if class == nil {
+		return nil, true
+	}
+	if sub := class.Subroutine(name); sub != nil {
+		return sub, true
+	}
+	return nil, ci.partial[className]
+}
+
+// Returns the names of the classes in the index, sorted
+func (ci *ClassIndex) ClassNames() []string {
+	names := make([]string, 0, len(ci.classes))
+	for name := range ci.classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
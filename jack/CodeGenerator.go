@@ -0,0 +1,313 @@
+package jack
+
+import (
+	"io"
+	"strconv"
+)
+
+// Generates the VM code of a class from its abstract syntax tree
+type CodeGenerator struct {
+	vmw           *VMWriter
+	reporter      *ErrorReporter
+	scope         *ClassScope
+	class         *Class
+	sub           *Subroutine // subroutine being generated
+	charsetPolicy string      // one of the CHARSET_ policies
+}
+
+func CreateCodeGenerator(output io.Writer, reporter *ErrorReporter) *CodeGenerator {
+	cg := &CodeGenerator{vmw: CreateVMWriter(output), reporter: reporter, charsetPolicy: CHARSET_WARN}
+	return cg
+}
+
+// Sets how characters outside the Hack character set are handled in string constants,
+// policy is one of the CHARSET_ policies (CHARSET_WARN by default)
+func (cg *CodeGenerator) SetCharsetPolicy(policy string) {
+	cg.charsetPolicy = policy
+}
+
+func (cg *CodeGenerator) GenerateClass(scope *ClassScope) {
+	cg.class, cg.scope = scope.Class, scope
+	for _, sub := range cg.class.Subroutines {
+		cg.GenerateSubroutine(sub)
+	}
+}
+
+func (cg *CodeGenerator) GenerateSubroutine(sub *Subroutine) {
+	cg.sub = sub
+	cg.vmw.WriteFunction(cg.class.Name+"."+sub.Name, cg.scope.SubroutineTable(sub).VarCount(VAR))
+	switch sub.Kind {
+	case "method":
+		{
+			cg.vmw.WritePush(ARG, 0)
+			cg.vmw.WritePop(POINTER, 0)
+		}
+	case "constructor":
+		{
+			cg.vmw.WritePush(CONSTANT, cg.scope.Table.VarCount(FIELD))
+			cg.vmw.WriteCall("Memory.alloc", 1)
+			cg.vmw.WritePop(POINTER, 0)
+		}
+	}
+	cg.GenerateStatements(sub.Body)
+}
+
+func (cg *CodeGenerator) GenerateStatements(statements []Statement) {
+	for _, stmt := range statements {
+		cg.GenerateStatement(stmt)
+	}
+}
+
+func (cg *CodeGenerator) GenerateStatement(stmt Statement) {
+	switch s := stmt.(type) {
+	case *LetStmt:
+		{
+			symbolKind, _, symbolIndex := cg.lookup(s.Name)
+			if s.Index == nil {
+				cg.GenerateExpression(s.Value)
+				cg.vmw.WritePop(cg.vmw.getSegmentOf(symbolKind), symbolIndex)
+				return
+			}
+			cg.GenerateExpression(s.Index)
+			cg.vmw.WritePush(cg.vmw.getSegmentOf(symbolKind), symbolIndex)
+			cg.vmw.WriteArithmetic(ADD)
+			cg.GenerateExpression(s.Value)
+			cg.vmw.WritePop(TEMP, 0)
+			cg.vmw.WritePop(POINTER, 1)
+			cg.vmw.WritePush(TEMP, 0)
+			cg.vmw.WritePop(THAT, 0)
+		}
+	case *IfStmt:
+		{
+			cg.GenerateExpression(s.Cond)
+			if_true_label := "IF_" + cg.vmw.CreateLabel()
+			if_false_label := "FALSEIF_" + cg.vmw.CreateLabel()
+			if_continuation_label := "CONTIF_" + cg.vmw.CreateLabel()
+			cg.vmw.WriteIf(if_true_label)
+			cg.vmw.WriteGoTo(if_false_label)
+			cg.vmw.WriteLabel(if_true_label)
+			cg.GenerateStatements(s.Then)
+			if s.HasElse {
+				cg.vmw.WriteGoTo(if_continuation_label)
+				cg.vmw.WriteLabel(if_false_label)
+				cg.GenerateStatements(s.Else)
+				cg.vmw.WriteLabel(if_continuation_label)
+			} else {
+				cg.vmw.WriteLabel(if_false_label)
+			}
+		}
+	case *WhileStmt:
+		{
+			while_exp_label := "WHILE_EXP_" + cg.vmw.CreateLabel()
+			while_end_label := "WHILE_END_" + cg.vmw.CreateLabel()
+			cg.vmw.WriteLabel(while_exp_label)
+			cg.GenerateExpression(s.Cond)
+			cg.vmw.WriteArithmetic(NOT)
+			cg.vmw.WriteIf(while_end_label)
+			cg.GenerateStatements(s.Body)
+			cg.vmw.WriteGoTo(while_exp_label)
+			cg.vmw.WriteLabel(while_end_label)
+		}
+	case *DoStmt:
+		{
+			cg.generateCall(s.Call)
+			cg.vmw.WritePop(TEMP, 0) // pop the return value
+		}
+	case *ReturnStmt:
+		{
+			if s.Value == nil {
+				cg.vmw.WritePush(CONSTANT, 0)
+			} else {
+				cg.GenerateExpression(s.Value)
+			}
+			cg.vmw.WriteReturn()
+		}
+	}
+}
+
+func (cg *CodeGenerator) GenerateExpression(expr Expression) {
+	switch e := expr.(type) {
+	case *IntLiteral:
+		{
+			cg.vmw.WritePushConstant(e.Value)
+		}
+	case *CharLiteral:
+		{
+			cg.vmw.WritePushConstant(e.Value)
+		}
+	case *StringLiteral:
+		{
+			codes := cg.hackCodes(e)
+			cg.vmw.WritePush(CONSTANT, len(codes))
+			cg.vmw.WriteCall("String.new", 1)
+			for _, code := range codes {
+				cg.vmw.WritePush(CONSTANT, code)
+				cg.vmw.WriteCall("String.appendChar", 2)
+			}
+		}
+	case *KeywordLiteral:
+		{
+			switch e.Keyword {
+			case "this":
+				{
+					cg.vmw.WritePush(POINTER, 0)
+				}
+			case "null", "false":
+				{
+					cg.vmw.WritePush(CONSTANT, 0)
+				}
+			case "true":
+				{
+					cg.vmw.WritePush(CONSTANT, 0)
+					cg.vmw.WriteArithmetic(NOT)
+				}
+			}
+		}
+	case *VarRef:
+		{
+			symbolKind, _, symbolIndex := cg.lookup(e.Name)
+			cg.vmw.WritePush(cg.vmw.getSegmentOf(symbolKind), symbolIndex)
+		}
+	case *IndexExpr:
+		{
+			symbolKind, _, symbolIndex := cg.lookup(e.Name)
+			cg.GenerateExpression(e.Index)
+			cg.vmw.WritePush(cg.vmw.getSegmentOf(symbolKind), symbolIndex)
+			cg.vmw.WriteArithmetic(ADD)
+			cg.vmw.WritePop(POINTER, 1)
+			cg.vmw.WritePush(THAT, 0)
+		}
+	case *CallExpr:
+		{
+			cg.generateCall(e)
+		}
+	case *UnaryExpr:
+		{
+			cg.GenerateExpression(e.Operand)
+			if e.Op == "~" {
+				cg.vmw.WriteArithmetic(NOT)
+			} else {
+				cg.vmw.WriteArithmetic(NEG)
+			}
+		}
+	case *BinaryExpr:
+		{
+			cg.GenerateExpression(e.Left)
+			cg.GenerateExpression(e.Right)
+			cg.generateOp(e.Op)
+		}
+	case *ParenExpr:
+		{
+			cg.GenerateExpression(e.Inner)
+		}
+	}
+}
+
+// Generates a subroutine call. The object of a method is pushed as its first argument: the variable
+// the method is called on, or the current object for an unqualified call, which calls a method unless
+// the class declares a function or constructor of that name.
+func (cg *CodeGenerator) generateCall(call *CallExpr) {
+	nArgs := len(call.Args)
+	callName := ""
+	if call.Receiver == "" {
+		callName = cg.class.Name + "." + call.Name
+		if sub := cg.class.Subroutine(call.Name); sub == nil || sub.Kind == "method" {
+			cg.vmw.WritePush(POINTER, 0)
+			nArgs++
+		}
+	} else if varKind, varType, varIndex := cg.lookup(call.Receiver); varKind != NONE {
+		callName = varType + "." + call.Name
+		cg.vmw.WritePush(cg.vmw.getSegmentOf(varKind), varIndex)
+		nArgs++
+	} else {
+		callName = call.Receiver + "." + call.Name
+	}
+	for _, arg := range call.Args {
+		cg.GenerateExpression(arg)
+	}
+	cg.vmw.WriteCall(callName, nArgs)
+}
+
+func (cg *CodeGenerator) generateOp(op string) {
+	switch op {
+	case "+":
+		{
+			cg.vmw.WriteArithmetic(ADD)
+		}
+	case "-":
+		{
+			cg.vmw.WriteArithmetic(SUB)
+		}
+	case "*":
+		{
+			cg.vmw.WriteCall("Math.multiply", 2)
+		}
+	case "/":
+		{
+			cg.vmw.WriteCall("Math.divide", 2)
+		}
+	case "&":
+		{
+			cg.vmw.WriteArithmetic(AND)
+		}
+	case "|":
+		{
+			cg.vmw.WriteArithmetic(OR)
+		}
+	case "<":
+		{
+			cg.vmw.WriteArithmetic(LT)
+		}
+	case ">":
+		{
+			cg.vmw.WriteArithmetic(GT)
+		}
+	case "=":
+		{
+			cg.vmw.WriteArithmetic(EQ)
+		}
+	}
+}
+
+// Returns the kind, type and index of a variable, looking in the subroutine scope first,
+// the kind is NONE if the name is not a variable
+func (cg *CodeGenerator) lookup(name string) (string, string, int) {
+	symbol, _ := cg.scope.Lookup(cg.sub, name)
+	return symbol.Kind, symbol.Type, symbol.Index
+}
+
+// Converts a string constant to Hack character codes,
+// characters outside the Hack character set are handled according to the charset policy
+func (cg *CodeGenerator) hackCodes(lit *StringLiteral) []int {
+	str := lit.Value
+	// the characters are where they are in the source unless escape sequences made the value shorter
+	inSource := lit.End.Offset-lit.Pos.Offset == len(str)+len(`""`)
+	codes := make([]int, 0, len(str))
+	index := 0
+	for offset, ch := range str {
+		index++
+		if code, ok := HackCharCode(ch); ok {
+			codes = append(codes, code)
+			continue
+		}
+		rng := Range{Start: lit.Pos, End: lit.End}
+		if inSource {
+			rng = nameRange(lit.Pos, `"`+str[:offset])
+			rng = nameRange(rng.End, string(ch))
+		}
+		problem := "character " + strconv.Itoa(index) + " of the string constant is " + describeRune(ch) + ", which is not in the Hack character set"
+		if cg.charsetPolicy == CHARSET_ERROR {
+			cg.reporter.Error(CODE_NON_HACK_CHARACTER, rng, problem)
+			continue
+		}
+		if replacement, ok := asciiTransliterations[ch]; ok && cg.charsetPolicy == CHARSET_TRANSLITERATE {
+			for _, r := range replacement {
+				codes = append(codes, int(r))
+			}
+			continue
+		}
+		cg.reporter.Warning(CODE_NON_HACK_CHARACTER, rng, problem+"; replaced with "+strconv.QuoteRune(CHARSET_REPLACEMENT))
+		codes = append(codes, CHARSET_REPLACEMENT)
+	}
+	return codes
+}
@@ -0,0 +1,177 @@
+package jack_test
+
+import (
+	"strings"
+	"testing"
+
+	"compiler/jack"
+)
+
+func TestNonHackCharacterRanges(t *testing.T) {
+	tests := []struct {
+		literal string
+		escapes bool
+		want    []string // text of the source under each diagnostic
+	}{
+		{`"hé wö"`, false, []string{"é", "ö"}},
+		{`"hé wö"`, true, []string{"é", "ö"}},
+		{`"h\"é"`, true, []string{`"h\"é"`}}, // the characters of the value aren't where they are in the source
+	}
+	for _, test := range tests {
+		diagnostics, underlined := compileMain(t, "do Output.printString("+test.literal+"); return;", jack.Options{Escapes: test.escapes})
+		if len(diagnostics) != len(test.want) {
+			t.Fatalf("%s, escapes %v: got diagnostics %v, want %d", test.literal, test.escapes, diagnostics, len(test.want))
+		}
+		for i, d := range diagnostics {
+			if d.Code != jack.CODE_NON_HACK_CHARACTER {
+				t.Errorf("%s, escapes %v: got diagnostic %v, want %s", test.literal, test.escapes, d, jack.CODE_NON_HACK_CHARACTER)
+			}
+			if underlined[i] != test.want[i] {
+				t.Errorf("%s, escapes %v: diagnostic %d underlines %q, want %q", test.literal, test.escapes, i, underlined[i], test.want[i])
+			}
+		}
+	}
+}
+
+// With the error policy a class with non-Hack characters has no VM code
+func TestNonHackCharacterErrors(t *testing.T) {
+	source := `class Main { function void main() { do Output.printString("café"); return; } }`
+	outputs, diagnostics := compileSources(t, map[string]string{"Main.jack": source}, jack.Options{Charset: jack.CHARSET_ERROR})
+	if len(diagnostics) != 1 || diagnostics[0].Severity != jack.SEVERITY_ERROR {
+		t.Errorf("got diagnostics %v, want one error", diagnostics)
+	}
+	if vm, ok := outputs["Main1.vm"]; ok {
+		t.Errorf("got VM code despite the error:\n%s", vm)
+	}
+}
+
+// The calls are lowered as the official compiler does, from the declaration of the called subroutine
+func TestCallForms(t *testing.T) {
+	const b = `class B {
+    method void m(int x) { return; }
+    function void f(int x) { return; }
+}`
+	tests := []struct {
+		form string
+		a    string // class A, compiled with B
+		want string // VM code of A
+	}{
+		{"unqualified method", `class A {
+    method void run() { do g(1); return; }
+    method void g(int x) { return; }
+}`, `function A.run 0
+push argument 0
+pop pointer 0
+push pointer 0
+push constant 1
+call A.g 2
+pop temp 0
+push constant 0
+return
+function A.g 0
+push argument 0
+pop pointer 0
+push constant 0
+return
+`},
+		{"unqualified function", `class A {
+    method void run() { do g(1); return; }
+    function void g(int x) { return; }
+}`, `function A.run 0
+push argument 0
+pop pointer 0
+push constant 1
+call A.g 1
+pop temp 0
+push constant 0
+return
+function A.g 0
+push constant 0
+return
+`},
+		{"var.method()", `class A {
+    field B member;
+    method void run() { var B local; do local.m(1); do member.m(2); return; }
+}`, `function A.run 1
+push argument 0
+pop pointer 0
+push local 0
+push constant 1
+call B.m 2
+pop temp 0
+push this 0
+push constant 2
+call B.m 2
+pop temp 0
+push constant 0
+return
+`},
+		{"Class.function()", `class A {
+    function void run() { do B.f(1); do Math.abs(2); return; }
+}`, `function A.run 0
+push constant 1
+call B.f 1
+pop temp 0
+push constant 2
+call Math.abs 1
+pop temp 0
+push constant 0
+return
+`},
+		{"constructor", `class A {
+    field int x, y;
+    constructor A new(int v) { let y = v; return this; }
+    function A make() { return A.new(1); }
+}`, `function A.new 0
+push constant 2
+call Memory.alloc 1
+pop pointer 0
+push argument 0
+pop this 1
+push pointer 0
+return
+function A.make 0
+push constant 1
+call A.new 1
+return
+`},
+		{"method call inside Main", `class Main {
+    function void main() { var Main m; do m.run(); return; }
+    method void run() { do step(); return; }
+    method void step() { return; }
+}`, `function Main.main 1
+push local 0
+call Main.run 1
+pop temp 0
+push constant 0
+return
+function Main.run 0
+push argument 0
+pop pointer 0
+push pointer 0
+call Main.step 1
+pop temp 0
+push constant 0
+return
+function Main.step 0
+push argument 0
+pop pointer 0
+push constant 0
+return
+`},
+	}
+	for _, test := range tests {
+		name := "A.jack"
+		if strings.HasPrefix(test.a, "class Main") {
+			name = "Main.jack"
+		}
+		outputs, diagnostics := compileSources(t, map[string]string{name: test.a, "B.jack": b}, jack.Options{})
+		if len(diagnostics) != 0 {
+			t.Errorf("%s: unexpected diagnostics %v", test.form, diagnostics)
+			continue
+		}
+		if got := string(outputs[jack.OutputName(name, jack.VM_SUFFIX)]); got != test.want {
+			t.Errorf("%s: got\n%swant\n%s", test.form, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,637 @@
+package jack
+
+import (
+	"io"
+	"os"
+)
+
+// Parses a class into its abstract syntax tree, code is generated from the tree by the CodeGenerator.
+//
+// A syntax error doesn't stop the parsing: it is reported, and the engine keeps parsing without reporting
+// further syntax errors until it synchronizes at the start of the next statement or class member.
+// This way a single run reports every syntax error of a file, but not the ones caused by an earlier error.
+//
+// All the state of a compilation is kept in its engine, code generator and writers, so files can be compiled
+// concurrently as long as each goroutine has its own.
+type CompilationEngine struct {
+	jt                    *JackTokenizer
+	xw                    *XMLWriter // parse tree output, nil unless requested
+	reporter              *ErrorReporter
+	reportedLexicalErrors int      // number of the tokenizer's errors already reported
+	recovering            bool     // a syntax error was reported and the engine hasn't synchronized since
+	prevEnd               Position // end of the last consumed token
+}
+
+func CreateCompilationEngine(inputFile *os.File, reporter *ErrorReporter) *CompilationEngine {
+	cEngine := &CompilationEngine{jt: CreateTokenizer(inputFile), reporter: reporter}
+	return cEngine
+}
+
+// Creates an engine that parses the class read from input, fileName is only used in error messages
+func CreateCompilationEngineFromReader(fileName string, input io.Reader, reporter *ErrorReporter) *CompilationEngine {
+	cEngine := &CompilationEngine{jt: CreateTokenizerFromReader(fileName, input), reporter: reporter}
+	return cEngine
+}
+
+// Turns on escape sequences in string constants, see JackTokenizer.EnableEscapes
+func (cEngine *CompilationEngine) EnableEscapes() {
+	cEngine.jt.EnableEscapes()
+}
+
+// Makes the engine also write the parse tree of the class to output, in the course's Xxx.xml format.
+// Output is closed at the end of the class if it is an io.Closer.
+func (cEngine *CompilationEngine) SetXMLOutput(output io.Writer) {
+	cEngine.xw = CreateXMLWriter(output)
+}
+
+// Parses the class, the returned tree is only complete if no errors were reported
+func (cEngine *CompilationEngine) CompileClass() *Class {
+	class := &Class{Pos: cEngine.jt.Position()}
+	cEngine.openTag("class")
+	cEngine.expect("class")
+	name := cEngine.expectIdentifier() // class name
+	class.Name, class.NamePos = name.Name, name.Pos
+	cEngine.expect("{")
+	if cEngine.recovering {
+		cEngine.syncClassMember()
+	}
+
+	// field or static variables, then subroutines
+	seenSubroutine := false
+	for cEngine.jt.HasMoreTokens() && !cEngine.atToken("}") {
+		switch cEngine.jt.KeyWord() {
+		case "field", "static":
+			{
+				if seenSubroutine {
+					cEngine.syntaxError("a subroutine declaration", "class variables are declared before the subroutines")
+				}
+				class.VarDecs = append(class.VarDecs, cEngine.CompileClassVarDec())
+			}
+		case "constructor", "function", "method":
+			{
+				seenSubroutine = true
+				class.Subroutines = append(class.Subroutines, cEngine.CompileSubroutine())
+			}
+		default:
+			{
+				cEngine.syntaxError("a class variable or subroutine declaration")
+				cEngine.advance()
+			}
+		}
+		if cEngine.recovering {
+			cEngine.syncClassMember()
+		}
+	}
+	cEngine.expect("}")
+	cEngine.closeTag("class")
+	if cEngine.jt.HasMoreTokens() {
+		cEngine.syntaxError("end of file", "a file holds a single class")
+	}
+	for cEngine.jt.HasMoreTokens() { // scan the rest of the file for its lexical errors
+		cEngine.jt.Advance()
+	}
+	cEngine.reportLexicalErrors()
+	if cEngine.xw != nil {
+		cEngine.xw.Close()
+	}
+	return class
+}
+
+func (cEngine *CompilationEngine) CompileClassVarDec() *ClassVarDec {
+	cEngine.openTag("classVarDec")
+	dec := &ClassVarDec{Pos: cEngine.jt.Position(), Kind: cEngine.jt.CurrentToken()}
+	cEngine.advance() // type
+	dec.TypePos = cEngine.jt.Position()
+	dec.Type = cEngine.compileType()
+	dec.Names = append(dec.Names, cEngine.expectIdentifier())
+
+	// check for more variables of same type in this line
+	for cEngine.atToken(",") {
+		cEngine.advance() // variable name
+		dec.Names = append(dec.Names, cEngine.expectIdentifier())
+	}
+	cEngine.expect(";")
+	cEngine.closeTag("classVarDec")
+	return dec
+}
+
+func (cEngine *CompilationEngine) CompileSubroutine() *Subroutine {
+	cEngine.openTag("subroutineDec")
+	sub := &Subroutine{Pos: cEngine.jt.Position(), Kind: cEngine.jt.CurrentToken()}
+	cEngine.advance() // type
+	switch sub.Kind {
+	case "constructor":
+		{
+			returnType := cEngine.expectIdentifier()
+			sub.ReturnType, sub.ReturnTypePos = returnType.Name, returnType.Pos
+			sub.Name, sub.NamePos = "new", cEngine.jt.Position()
+			if cEngine.jt.Identifier() == "new" {
+				cEngine.advance()
+			} else {
+				cEngine.syntaxError("'new'", "constructors are named 'new'")
+			}
+		}
+	case "method", "function":
+		{
+			sub.ReturnTypePos = cEngine.jt.Position()
+			sub.ReturnType = cEngine.compileType()
+			name := cEngine.expectIdentifier() // method/function name
+			sub.Name, sub.NamePos = name.Name, name.Pos
+		}
+	}
+	cEngine.expect("(")
+
+	// check for parameters
+	sub.Params = cEngine.CompileParameterList()
+	cEngine.expect(")")
+	cEngine.CompileSubroutineBody(sub)
+	cEngine.closeTag("subroutineDec")
+	return sub
+}
+
+// Parses the local variables and statements of sub
+func (cEngine *CompilationEngine) CompileSubroutineBody(sub *Subroutine) {
+	cEngine.openTag("subroutineBody")
+	cEngine.expect("{")
+	//check for var decs
+	for cEngine.atToken("var") {
+		sub.Locals = append(sub.Locals, cEngine.CompileVarDec())
+		if cEngine.recovering {
+			cEngine.syncStatement()
+		}
+	}
+	//check for statements
+	sub.Body = cEngine.CompileStatements()
+	cEngine.expect("}")
+	cEngine.closeTag("subroutineBody")
+}
+
+func (cEngine *CompilationEngine) CompileParameterList() []*Parameter {
+	cEngine.openTag("parameterList")
+	params := make([]*Parameter, 0)
+	for cEngine.jt.TokenType() == KEYWORD || cEngine.jt.TokenType() == IDENTIFIER {
+		param := &Parameter{Pos: cEngine.jt.Position()}
+		param.Type = cEngine.compileType()
+		name := cEngine.expectIdentifier() // parameter name
+		param.Name, param.NamePos = name.Name, name.Pos
+		params = append(params, param)
+		//check for more parameters
+		if cEngine.atToken(",") {
+			cEngine.advance()
+		}
+	}
+	cEngine.closeTag("parameterList")
+	return params
+}
+
+func (cEngine *CompilationEngine) CompileVarDec() *VarDec {
+	cEngine.openTag("varDec")
+	dec := &VarDec{Pos: cEngine.jt.Position()}
+	cEngine.advance() // var type
+	dec.TypePos = cEngine.jt.Position()
+	dec.Type = cEngine.compileType()
+	dec.Names = append(dec.Names, cEngine.expectIdentifier())
+	for cEngine.atToken(",") {
+		cEngine.advance() // var name
+		dec.Names = append(dec.Names, cEngine.expectIdentifier())
+	}
+	cEngine.expect(";")
+	cEngine.closeTag("varDec")
+	return dec
+}
+
+// Parses statements up to the '}' that closes them
+func (cEngine *CompilationEngine) CompileStatements() []Statement {
+	cEngine.openTag("statements")
+	statements := make([]Statement, 0)
+	for cEngine.jt.HasMoreTokens() && !cEngine.atToken("}") && !cEngine.isClassMember(cEngine.jt.KeyWord()) {
+		switch cEngine.jt.KeyWord() {
+		case "let":
+			{
+				statements = append(statements, cEngine.CompileLet())
+			}
+		case "if":
+			{
+				statements = append(statements, cEngine.CompileIf())
+			}
+		case "while":
+			{
+				statements = append(statements, cEngine.CompileWhile())
+			}
+		case "do":
+			{
+				statements = append(statements, cEngine.CompileDo())
+			}
+		case "return":
+			{
+				statements = append(statements, cEngine.CompileReturn())
+			}
+		default:
+			{
+				cEngine.syntaxError("a statement", cEngine.statementHints()...)
+				cEngine.advance()
+			}
+		}
+		if cEngine.recovering {
+			cEngine.syncStatement()
+		}
+	}
+	cEngine.closeTag("statements")
+	return statements
+}
+
+func (cEngine *CompilationEngine) CompileLet() *LetStmt {
+	cEngine.openTag("letStatement")
+	stmt := &LetStmt{Pos: cEngine.jt.Position()}
+	cEngine.advance() // var name
+	name := cEngine.expectIdentifier()
+	stmt.Name, stmt.NamePos = name.Name, name.Pos
+	if cEngine.atToken("[") {
+		cEngine.advance()
+		stmt.Index = cEngine.CompileExpression()
+		cEngine.expect("]")
+	}
+	cEngine.expect("=")
+	stmt.Value = cEngine.CompileExpression()
+	cEngine.expect(";")
+	cEngine.closeTag("letStatement")
+	return stmt
+}
+
+func (cEngine *CompilationEngine) CompileIf() *IfStmt {
+	cEngine.openTag("ifStatement")
+	stmt := &IfStmt{Pos: cEngine.jt.Position()}
+	cEngine.advance()
+	cEngine.expect("(")
+	stmt.Cond = cEngine.CompileExpression()
+	cEngine.expect(")")
+	cEngine.expect("{")
+	stmt.Then = cEngine.CompileStatements()
+	cEngine.expect("}")
+	if cEngine.atToken("else") {
+		stmt.HasElse = true
+		cEngine.advance()
+		cEngine.expect("{")
+		stmt.Else = cEngine.CompileStatements()
+		cEngine.expect("}")
+	}
+	cEngine.closeTag("ifStatement")
+	return stmt
+}
+
+func (cEngine *CompilationEngine) CompileWhile() *WhileStmt {
+	cEngine.openTag("whileStatement")
+	stmt := &WhileStmt{Pos: cEngine.jt.Position()}
+	cEngine.advance() // "("
+	cEngine.expect("(")
+	stmt.Cond = cEngine.CompileExpression()
+	cEngine.expect(")")
+	cEngine.expect("{")
+	stmt.Body = cEngine.CompileStatements()
+	cEngine.expect("}")
+	cEngine.closeTag("whileStatement")
+	return stmt
+}
+
+func (cEngine *CompilationEngine) CompileDo() *DoStmt {
+	cEngine.openTag("doStatement")
+	stmt := &DoStmt{Pos: cEngine.jt.Position()}
+	cEngine.advance() // subroutineName or className/varName
+	name := cEngine.expectIdentifier()
+	stmt.Call = cEngine.CompileSubroutineCall(name.Name, name.Pos)
+	cEngine.expect(";")
+	cEngine.closeTag("doStatement")
+	return stmt
+}
+
+func (cEngine *CompilationEngine) CompileReturn() *ReturnStmt {
+	cEngine.openTag("returnStatement")
+	stmt := &ReturnStmt{Pos: cEngine.jt.Position()}
+	cEngine.advance() // ; or experssion
+	// a '}' means the ';' is missing rather than the expression
+	if !cEngine.atToken(";") && !cEngine.atToken("}") {
+		stmt.Value = cEngine.CompileExpression()
+	}
+	cEngine.expect(";")
+	cEngine.closeTag("returnStatement")
+	return stmt
+}
+
+func (cEngine *CompilationEngine) CompileExpression() Expression {
+	cEngine.openTag("expression")
+	expr := cEngine.CompileTerm()
+	for cEngine.isOp(cEngine.jt.CurrentToken()) {
+		opPos := cEngine.jt.Position()
+		op := cEngine.CompileOp()
+		right := cEngine.CompileTerm()
+		expr = &BinaryExpr{Pos: opPos, End: cEngine.prevEnd, Op: op, Left: expr, Right: right}
+	}
+	cEngine.closeTag("expression")
+	return expr
+}
+
+// Parses a term, on a syntax error it returns nil without consuming anything
+func (cEngine *CompilationEngine) CompileTerm() Expression {
+	cEngine.openTag("term")
+	defer cEngine.closeTag("term")
+	pos := cEngine.jt.Position()
+	switch cEngine.jt.TokenType() {
+	case KEYWORD:
+		{
+			keyword := cEngine.jt.KeyWord()
+			if keyword == "this" || keyword == "null" || keyword == "true" || keyword == "false" {
+				cEngine.advance()
+				return &KeywordLiteral{Pos: pos, End: cEngine.prevEnd, Keyword: keyword}
+			}
+		}
+	case INT_CONST:
+		{
+			lit := &IntLiteral{Pos: pos, End: cEngine.jt.End(), Value: cEngine.jt.IntVal()}
+			cEngine.advance()
+			return lit
+		}
+	case CHAR_CONST:
+		{
+			lit := &CharLiteral{Pos: pos, End: cEngine.jt.End(), Value: cEngine.jt.IntVal(), Text: cEngine.jt.CurrentToken()}
+			cEngine.advance()
+			return lit
+		}
+	case STRING_CONST:
+		{
+			lit := &StringLiteral{Pos: pos, End: cEngine.jt.End(), Value: cEngine.jt.StringVal()}
+			cEngine.advance()
+			return lit
+		}
+	case IDENTIFIER: //varName or subroutineCall
+		{
+			varName := cEngine.jt.CurrentToken()
+			cEngine.advance()
+			if cEngine.atToken("(") || cEngine.atToken(".") { // subroutineCall
+				return cEngine.CompileSubroutineCall(varName, pos)
+			}
+			if cEngine.atToken("[") { // varName [experssion]
+				cEngine.advance()
+				index := cEngine.CompileExpression()
+				cEngine.expect("]")
+				return &IndexExpr{Pos: pos, End: cEngine.prevEnd, Name: varName, Index: index}
+			}
+			return &VarRef{Pos: pos, End: cEngine.prevEnd, Name: varName}
+		}
+	case SYMBOL:
+		{
+			switch cEngine.jt.CurrentToken() {
+			case "~", "-": //unaryOp term
+				{
+					op := cEngine.jt.CurrentToken()
+					cEngine.advance()
+					operand := cEngine.CompileTerm()
+					return &UnaryExpr{Pos: pos, End: cEngine.prevEnd, Op: op, Operand: operand}
+				}
+			case "(": // (expression)
+				{
+					cEngine.advance()
+					inner := cEngine.CompileExpression()
+					cEngine.expect(")")
+					return &ParenExpr{Pos: pos, End: cEngine.prevEnd, Inner: inner}
+				}
+			}
+		}
+	}
+	cEngine.syntaxError("an expression")
+	return nil
+}
+
+func (cEngine *CompilationEngine) CompileExpressionList() []Expression {
+	cEngine.openTag("expressionList")
+	exprs := make([]Expression, 0)
+	if !cEngine.atToken(")") { // no more experssions
+		exprs = append(exprs, cEngine.CompileExpression())
+		for cEngine.atToken(",") {
+			cEngine.advance()
+			exprs = append(exprs, cEngine.CompileExpression())
+		}
+	}
+	cEngine.closeTag("expressionList")
+	return exprs
+}
+
+// Parses the rest of a subroutine call whose first identifier, name, was already consumed
+func (cEngine *CompilationEngine) CompileSubroutineCall(name string, pos Position) *CallExpr {
+	call := &CallExpr{Pos: pos, Name: name, NamePos: pos}
+	if cEngine.atToken(".") {
+		cEngine.advance() // subrountineName
+		subroutineName := cEngine.expectIdentifier()
+		call.Receiver = name
+		call.Name, call.NamePos = subroutineName.Name, subroutineName.Pos
+	}
+	cEngine.expect("(")
+	call.Args = cEngine.CompileExpressionList()
+	cEngine.expect(")")
+	call.End = cEngine.prevEnd
+	return call
+}
+
+func (cEngine *CompilationEngine) CompileOp() string {
+	op := cEngine.jt.CurrentToken()
+	cEngine.advance()
+	return op
+}
+
+// Consumes a type: int, char, boolean, void or a class name
+func (cEngine *CompilationEngine) compileType() string {
+	if cEngine.jt.TokenType() != KEYWORD && cEngine.jt.TokenType() != IDENTIFIER {
+		cEngine.syntaxError("a type")
+		return ""
+	}
+	sType := cEngine.jt.CurrentToken()
+	cEngine.advance()
+	return sType
+}
+
+// Writes the current token to the parse tree (if requested) and moves to the next one
+func (cEngine *CompilationEngine) advance() {
+	if cEngine.xw != nil && cEngine.jt.HasMoreTokens() {
+		cEngine.xw.WriteTerminal(cEngine.jt.TokenType().String(), cEngine.jt.CurrentToken())
+	}
+	if cEngine.jt.HasMoreTokens() {
+		cEngine.prevEnd = cEngine.jt.End()
+	}
+	cEngine.jt.Advance()
+}
+
+func (cEngine *CompilationEngine) openTag(tag string) {
+	if cEngine.xw != nil {
+		cEngine.xw.OpenTag(tag)
+	}
+}
+
+func (cEngine *CompilationEngine) closeTag(tag string) {
+	if cEngine.xw != nil {
+		cEngine.xw.CloseTag(tag)
+	}
+}
+
+// Tells whether the current token is the given keyword or symbol
+func (cEngine *CompilationEngine) atToken(token string) bool {
+	tokenType := cEngine.jt.TokenType()
+	return (tokenType == KEYWORD || tokenType == SYMBOL) && cEngine.jt.CurrentToken() == token
+}
+
+// Consumes the given keyword or symbol, if it's not the current token a syntax error is reported and nothing is consumed
+func (cEngine *CompilationEngine) expect(token string) {
+	if !cEngine.atToken(token) {
+		if token == ";" && cEngine.prevEnd.Line > 0 && cEngine.jt.Position().Line > cEngine.prevEnd.Line {
+			// the ';' is missing at the end of the previous line rather than before the token on the next one
+			cEngine.syntaxErrorAt(Range{Start: cEngine.prevEnd, End: cEngine.prevEnd}, "';'")
+			return
+		}
+		cEngine.syntaxError("'" + token + "'")
+		return
+	}
+	cEngine.advance()
+}
+
+// Consumes an identifier, if the current token isn't one a syntax error is reported and nothing is consumed
+func (cEngine *CompilationEngine) expectIdentifier() Ident {
+	ident := Ident{Pos: cEngine.jt.Position()}
+	if cEngine.jt.TokenType() != IDENTIFIER {
+		if cEngine.jt.TokenType() == KEYWORD {
+			cEngine.syntaxError(IDENTIFIER.String(), "'"+cEngine.jt.KeyWord()+"' is a keyword and can't be used as a name")
+		} else {
+			cEngine.syntaxError(IDENTIFIER.String())
+		}
+		return ident
+	}
+	ident.Name = cEngine.jt.CurrentToken()
+	cEngine.advance()
+	return ident
+}
+
+// Reports that the current token isn't the expected one as "expected X, found Y", with optional hints
+// on how to fix it, unless the engine is still recovering from a previous syntax error
+func (cEngine *CompilationEngine) syntaxError(expected string, hints ...string) {
+	cEngine.syntaxErrorAt(Range{Start: cEngine.jt.Position(), End: cEngine.jt.End()}, expected, hints...)
+}
+
+// Same as syntaxError, for an error located at rng rather than at the current token
+func (cEngine *CompilationEngine) syntaxErrorAt(rng Range, expected string, hints ...string) {
+	if cEngine.recovering {
+		return
+	}
+	cEngine.reportLexicalErrors() // they precede the current token, and may well be the reason for this error
+	cEngine.reporter.Report(Diagnostic{
+		Severity: SEVERITY_ERROR,
+		Code:     CODE_SYNTAX,
+		Message:  "expected " + expected + ", found " + cEngine.describeCurrentToken(),
+		File:     rng.Start.File,
+		Range:    rng,
+		Label:    "expected " + expected,
+		Hints:    hints,
+	})
+	cEngine.recovering = true
+}
+
+// Guesses what is missing from a statement that doesn't start with a statement keyword
+func (cEngine *CompilationEngine) statementHints() []string {
+	if cEngine.jt.KeyWord() == "var" {
+		return []string{"local variables are declared at the start of the subroutine body, before the statements"}
+	}
+	if cEngine.jt.TokenType() != IDENTIFIER {
+		return nil
+	}
+	switch next := cEngine.jt.Peek(1); {
+	case next.Type == SYMBOL && (next.Text == "=" || next.Text == "["):
+		{
+			return []string{"did you forget 'let'?"}
+		}
+	case next.Type == SYMBOL && (next.Text == "(" || next.Text == "."):
+		{
+			return []string{"did you forget 'do'?"}
+		}
+	}
+	return nil
+}
+
+// Skips tokens after a syntax error up to the start of the next statement: past a ';',
+// or up to a '}', a var declaration or a keyword that starts a statement or a class member
+func (cEngine *CompilationEngine) syncStatement() {
+	for cEngine.jt.HasMoreTokens() {
+		if cEngine.atToken(";") {
+			cEngine.advance()
+			break
+		}
+		keyword := cEngine.jt.KeyWord()
+		if cEngine.atToken("}") || keyword == "var" || cEngine.isStatement(keyword) || cEngine.isClassMember(keyword) {
+			break
+		}
+		cEngine.advance()
+	}
+	cEngine.recovering = !cEngine.jt.HasMoreTokens() // nothing is left to report at the end of the file
+}
+
+// Skips tokens after a syntax error up to the keyword that starts the next class member
+func (cEngine *CompilationEngine) syncClassMember() {
+	for cEngine.jt.HasMoreTokens() && !cEngine.isClassMember(cEngine.jt.KeyWord()) {
+		cEngine.advance()
+	}
+	cEngine.recovering = !cEngine.jt.HasMoreTokens()
+}
+
+// Reports the errors the tokenizer found since the last call
+func (cEngine *CompilationEngine) reportLexicalErrors() {
+	lexicalErrors := cEngine.jt.Errors()
+	for _, err := range lexicalErrors[cEngine.reportedLexicalErrors:] {
+		cEngine.reporter.LexicalError(err)
+	}
+	cEngine.reportedLexicalErrors = len(lexicalErrors)
+}
+
+func (cEngine *CompilationEngine) describeCurrentToken() string {
+	if !cEngine.jt.HasMoreTokens() {
+		if err := cEngine.jt.Err(); err != nil {
+			return "end of file (" + err.Error() + ")"
+		}
+		return "end of file"
+	}
+	return "'" + cEngine.jt.CurrentToken() + "'"
+}
+
+func (cEngine *CompilationEngine) isOp(token string) bool {
+	if cEngine.jt.TokenType() != SYMBOL {
+		return false
+	}
+	if token == "+" ||
+		token == "-" ||
+		token == "*" ||
+		token == "/" ||
+		token == "&" ||
+		token == "|" ||
+		token == "<" ||
+		token == ">" ||
+		token == "=" {
+		return true
+	}
+	return false
+}
+
+func (cEngine *CompilationEngine) isStatement(token string) bool {
+	if token == "let" ||
+		token == "if" ||
+		token == "while" ||
+		token == "do" ||
+		token == "return" {
+		return true
+	}
+	return false
+}
+
+func (cEngine *CompilationEngine) isClassMember(token string) bool {
+	if token == "field" ||
+		token == "static" ||
+		token == "constructor" ||
+		token == "function" ||
+		token == "method" {
+		return true
+	}
+	return false
+}
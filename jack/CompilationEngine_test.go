@@ -0,0 +1,33 @@
+package jack_test
+
+import (
+	"strings"
+	"testing"
+
+	"compiler/jack"
+)
+
+// Nothing but comments may follow the class
+func TestParseAfterClass(t *testing.T) {
+	const class = "class Main { function void main() { return; } }"
+	tests := []struct {
+		rest string
+		want []string // codes of the diagnostics
+	}{
+		{"\n// end\n/* end */\n", nil},
+		{"\n/* unterminated", []string{jack.CODE_UNTERMINATED_COMMENT}},
+		{" class Other { }", []string{jack.CODE_SYNTAX}},
+		{" # $", []string{jack.CODE_ILLEGAL_CHARACTER, jack.CODE_ILLEGAL_CHARACTER}},
+		{" class Other { } # $", []string{jack.CODE_SYNTAX, jack.CODE_ILLEGAL_CHARACTER, jack.CODE_ILLEGAL_CHARACTER}},
+	}
+	for _, test := range tests {
+		_, diagnostics := jack.Parse("Main.jack", strings.NewReader(class+test.rest), jack.Options{})
+		got := make([]string, len(diagnostics))
+		for i, d := range diagnostics {
+			got[i] = d.Code
+		}
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%q: got diagnostics %v, want codes %v", test.rest, diagnostics, test.want)
+		}
+	}
+}
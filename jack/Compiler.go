@@ -0,0 +1,234 @@
+// Package jack compiles Jack classes to the VM code of the nand2tetris course.
+// Compile is the whole pipeline, Tokenize, Parse, ClassSymbolTable and SubroutineSymbolTable give access to its stages.
+package jack
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Suffixes of the outputs of a class, added to the name of its source without ".jack"
+const (
+	VM_SUFFIX     = "1.vm"  // VM code
+	XML_SUFFIX    = ".xml"  // parse tree, with Options.XML
+	TOKENS_SUFFIX = "T.xml" // tokens, with Options.Tokens
+)
+
+// Options of a compilation, the zero value compiles as the Jack specification says
+type Options struct {
+	Tokens      bool   // only tokenize the sources and output their XxxT.xml token files
+	XML         bool   // also output the Xxx.xml parse trees
+	Escapes     bool   // recognize escape sequences in string constants, see JackTokenizer.EnableEscapes
+	Charset     string // one of the CHARSET_ policies, CHARSET_WARN if empty
+	Incomplete  bool   // the sources are only part of the program, the names of the other classes are not reported
+	StrictTypes bool   // report type mismatches as errors instead of warnings, see TypeChecker
+	Jobs        int    // number of files compiled at the same time, 1 if it is less
+	// Names of sources only read for the declarations of their classes, such as the other files of the directory of
+	// a compiled file. They have no outputs and no diagnostics, and a compiled class replaces a class of the same name.
+	Declarations []string
+}
+
+// Returns the name of an output of the source sourceName, suffix is one of the _SUFFIX constants
+func OutputName(sourceName string, suffix string) string {
+	return strings.TrimSuffix(sourceName, ".jack") + suffix
+}
+
+// Compiles the classes of a program, sources maps file names such as "Main.jack" to their content.
+// The file names are the ones of the diagnostics and outputs, which are named after them with the _SUFFIX constants.
+//
+// Every class is parsed before the names used in each class are resolved against the declarations of the others,
+// then the types of the classes whose names resolve are checked. Options.Jobs files are parsed, then checked,
+// at the same time, a source is read by a single goroutine.
+// The VM code of a class is only output if no errors were reported for it, the parse tree is output even if it is
+// incomplete. The diagnostics are in the order of the file names. The returned error is only set if ctx ended
+// before every class was compiled, along with the outputs of the classes compiled until then and the diagnostics
+// found in the classes read until then.
+func Compile(ctx context.Context, sources map[string]io.Reader, opts Options) (map[string][]byte, []Diagnostic, error) {
+	declarationsOnly := make(map[string]bool)
+	for _, name := range opts.Declarations {
+		declarationsOnly[name] = true
+	}
+	names := make([]string, 0, len(sources)) // the compiled sources, then the declarations
+	declarations := make([]string, 0, len(opts.Declarations))
+	for name := range sources {
+		if declarationsOnly[name] {
+			declarations = append(declarations, name)
+		} else {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	sort.Strings(declarations)
+	compiledCount := len(names)
+	names = append(names, declarations...)
+
+	outputs := make(map[string][]byte)
+	diagnostics := make([]Diagnostic, 0)
+	classOutputs := make([]map[string][]byte, compiledCount) // the outputs of each compiled file, set once it is done
+	reporters := make([]*ErrorReporter, len(names))
+	collect := func() {
+		for i := range classOutputs {
+			for name, output := range classOutputs[i] {
+				outputs[name] = output
+			}
+			if reporters[i] != nil { // the file was read
+				diagnostics = append(diagnostics, reporters[i].Diagnostics()...)
+			}
+		}
+	}
+	if opts.Tokens {
+		err := forEach(ctx, compiledCount, opts.Jobs, func(i int) {
+			output := &bytes.Buffer{}
+			jt := createTokenizer(names[i], sources[names[i]], opts)
+			CreateXMLWriter(output).WriteTokens(jt)
+			reporters[i] = CreateErrorReporter()
+			reportLexicalErrors(jt, reporters[i])
+			classOutputs[i] = map[string][]byte{OutputName(names[i], TOKENS_SUFFIX): output.Bytes()}
+		})
+		collect()
+		return outputs, diagnostics, err
+	}
+
+	// parse every class, then index their declarations in the order of the files
+	classes := make([]*Class, len(names))
+	xmlOutputs := make([][]byte, len(names))
+	err := forEach(ctx, len(names), opts.Jobs, func(i int) {
+		reporters[i] = CreateErrorReporter()
+		cEngine := createCompilationEngine(names[i], sources[names[i]], reporters[i], opts)
+		xmlOutput := &bytes.Buffer{}
+		if opts.XML {
+			cEngine.SetXMLOutput(xmlOutput)
+		}
+		classes[i] = cEngine.CompileClass()
+		xmlOutputs[i] = xmlOutput.Bytes()
+	})
+	if opts.XML {
+		for i, name := range names[:compiledCount] {
+			if classes[i] != nil {
+				outputs[OutputName(name, XML_SUFFIX)] = xmlOutputs[i]
+			}
+		}
+	}
+	if err != nil {
+		collect()
+		return outputs, diagnostics, err
+	}
+	complete := !opts.Incomplete
+	for _, class := range classes {
+		complete = complete && class.Name != "" // else the declarations of a class are missing, it may be unreadable
+	}
+	index := CreateClassIndex(complete)
+	for i, class := range classes {
+		if first, ok := index.Add(class, reporters[i].ErrorCount() > 0); !ok && i < compiledCount {
+			reporters[i].Redeclaration(CODE_DUPLICATE_CLASS, nameRange(class.NamePos, class.Name),
+				"class '"+class.Name+"' is declared twice in the program", nameRange(first.NamePos, class.Name))
+		}
+	}
+
+	// check the classes that parsed without errors, and generate the code of the ones that pass the checks
+	err = forEach(ctx, compiledCount, opts.Jobs, func(i int) {
+		reporter := reporters[i]
+		var scope *ClassScope // the symbol tables shared by the passes over the class
+		if reporter.ErrorCount() == 0 {
+			scope = CreateClassScope(classes[i], reporter)
+			CreateResolver(index, reporter).ResolveClass(scope)
+		}
+		if reporter.ErrorCount() == 0 {
+			tc := CreateTypeChecker(index, reporter)
+			if opts.StrictTypes {
+				tc.SetStrict()
+			}
+			tc.CheckClass(scope)
+		}
+		classOutputs[i] = make(map[string][]byte)
+		if reporter.ErrorCount() == 0 {
+			output := &bytes.Buffer{}
+			cg := CreateCodeGenerator(output, reporter)
+			if opts.Charset != "" {
+				cg.SetCharsetPolicy(opts.Charset)
+			}
+			cg.GenerateClass(scope)
+			if reporter.ErrorCount() == 0 { // the charset policy may make characters errors
+				classOutputs[i][OutputName(names[i], VM_SUFFIX)] = output.Bytes()
+			}
+		}
+	})
+	collect()
+	return outputs, diagnostics, err
+}
+
+// Calls f with every index from 0 to n-1, from jobs goroutines at most.
+// Once ctx ended the remaining indexes are skipped and its error is returned.
+func forEach(ctx context.Context, n int, jobs int, f func(i int)) error {
+	if jobs < 1 {
+		jobs = 1
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				f(i)
+			}
+		}()
+	}
+	var err error
+	for i := 0; i < n && err == nil; i++ {
+		if err = ctx.Err(); err == nil {
+			indexes <- i
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return err
+}
+
+// Returns every token of the class read from input, with the lexical errors found in it.
+// fileName is the file of the positions of the tokens and diagnostics.
+func Tokenize(fileName string, input io.Reader, opts Options) ([]Token, []Diagnostic) {
+	jt := createTokenizer(fileName, input, opts)
+	tokens := make([]Token, 0)
+	for jt.HasMoreTokens() {
+		tokens = append(tokens, jt.Peek(0))
+		jt.Advance()
+	}
+	reporter := CreateErrorReporter()
+	reportLexicalErrors(jt, reporter)
+	return tokens, reporter.Diagnostics()
+}
+
+// Returns the abstract syntax tree of the class read from input, with the errors found in it.
+// The tree is only complete if there are no errors.
+func Parse(fileName string, input io.Reader, opts Options) (*Class, []Diagnostic) {
+	reporter := CreateErrorReporter()
+	class := createCompilationEngine(fileName, input, reporter, opts).CompileClass()
+	return class, reporter.Diagnostics()
+}
+
+func createTokenizer(fileName string, input io.Reader, opts Options) *JackTokenizer {
+	jt := CreateTokenizerFromReader(fileName, input)
+	if opts.Escapes {
+		jt.EnableEscapes()
+	}
+	return jt
+}
+
+func createCompilationEngine(fileName string, input io.Reader, reporter *ErrorReporter, opts Options) *CompilationEngine {
+	cEngine := CreateCompilationEngineFromReader(fileName, input, reporter)
+	if opts.Escapes {
+		cEngine.EnableEscapes()
+	}
+	return cEngine
+}
+
+func reportLexicalErrors(jt *JackTokenizer, reporter *ErrorReporter) {
+	for _, err := range jt.Errors() {
+		reporter.LexicalError(err)
+	}
+}
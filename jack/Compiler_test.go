@@ -0,0 +1,190 @@
+package jack_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"compiler/jack"
+)
+
+// Compiling the same program from several goroutines gives the same VM code every time, run it with -race
+func TestCompileConcurrently(t *testing.T) {
+	sources := map[string]string{
+		"Main.jack": `class Main {
+    function void main() {
+        var Counter c;
+        let c = Counter.new();
+        while (c.next() < 10) {
+            if (c.value() = 5) {
+                do Output.printString("five");
+            }
+        }
+        do c.dispose();
+        return;
+    }
+}
+`,
+		"Counter.jack": `class Counter {
+    field int count;
+    constructor Counter new() {
+        let count = 0;
+        return this;
+    }
+    method int next() {
+        let count = count + 1;
+        return count;
+    }
+    method int value() {
+        return count;
+    }
+    method void dispose() {
+        do Memory.deAlloc(this);
+        return;
+    }
+}
+`,
+	}
+	compile := func() map[string][]byte {
+		readers := make(map[string]io.Reader, len(sources))
+		for name, source := range sources {
+			readers[name] = bytes.NewReader([]byte(source))
+		}
+		outputs, diagnostics, err := jack.Compile(context.Background(), readers, jack.Options{})
+		if err != nil || len(diagnostics) != 0 {
+			t.Error(err, diagnostics)
+		}
+		return outputs
+	}
+
+	want := compile()
+	const goroutines = 16
+	results := make([]map[string][]byte, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = compile()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, outputs := range results {
+		for _, name := range []string{"Main1.vm", "Counter1.vm"} {
+			if !bytes.Equal(outputs[name], want[name]) {
+				t.Errorf("goroutine %d: %s differs:\n%s\nwant\n%s", i, name, outputs[name], want[name])
+			}
+		}
+	}
+	if t.Failed() {
+		return
+	}
+	// the labels are numbered from 0 in every class whichever goroutine compiles it
+	if !bytes.Contains(want["Main1.vm"], []byte("label WHILE_EXP_LABEL_0")) {
+		t.Errorf("Main1.vm doesn't start its labels at 0:\n%s", want["Main1.vm"])
+	}
+}
+
+// The sources only read for their declarations can't make the compiled sources fail
+func TestCompileDeclarations(t *testing.T) {
+	sources := map[string]io.Reader{
+		"Main.jack":   strings.NewReader(`class Main { function void main() { do Game.run(); return; } }`),
+		"Backup.jack": strings.NewReader(`class Main { function void main() { return; } }`),
+		"Broken.jack": strings.NewReader(`class Broken { method void f( { }`),
+		"Game.jack":   strings.NewReader(`class Game { function void run() { return; } }`),
+	}
+	opts := jack.Options{Declarations: []string{"Backup.jack", "Broken.jack", "Game.jack"}}
+	outputs, diagnostics, err := jack.Compile(context.Background(), sources, opts)
+	if err != nil || len(diagnostics) != 0 {
+		t.Fatal(err, diagnostics)
+	}
+	if len(outputs) != 1 || !bytes.Contains(outputs["Main1.vm"], []byte("call Game.run 0")) {
+		t.Errorf("got outputs %v, want only Main1.vm calling Game.run", outputs)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("unreadable")
+}
+
+// A source that can't be read fails alone, the names of its class aren't reported in the others
+func TestCompileUnreadableSource(t *testing.T) {
+	sources := map[string]io.Reader{
+		"Main.jack":   strings.NewReader(`class Main { function void main() { do Broken.f(); return; } }`),
+		"Broken.jack": failingReader{},
+	}
+	outputs, diagnostics, err := jack.Compile(context.Background(), sources, jack.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range diagnostics {
+		if d.File != "Broken.jack" {
+			t.Errorf("unexpected diagnostic %v", d)
+		}
+	}
+	if outputs["Main1.vm"] == nil {
+		t.Errorf("Main1.vm wasn't output")
+	}
+}
+
+// A reader that cancels a context once it is read
+type cancellingReader struct {
+	io.Reader
+	cancel context.CancelFunc
+}
+
+func (r cancellingReader) Read(p []byte) (int, error) {
+	r.cancel()
+	return r.Reader.Read(p)
+}
+
+// The diagnostics of the classes parsed before ctx ended are returned with its error
+func TestCompileCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sources := map[string]io.Reader{
+		"A.jack": cancellingReader{strings.NewReader(`class A { function void f() { return } }`), cancel},
+		"B.jack": strings.NewReader(`class B { }`),
+	}
+	_, diagnostics, err := jack.Compile(ctx, sources, jack.Options{})
+	if err != context.Canceled {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].File != "A.jack" || diagnostics[0].Code != jack.CODE_SYNTAX {
+		t.Errorf("got diagnostics %v, want the syntax error of A.jack", diagnostics)
+	}
+}
+
+// Compiles the classes of sources, which map file names to their content
+func compileSources(t *testing.T, sources map[string]string, opts jack.Options) (map[string][]byte, []jack.Diagnostic) {
+	t.Helper()
+	readers := make(map[string]io.Reader, len(sources))
+	for name, source := range sources {
+		readers[name] = strings.NewReader(source)
+	}
+	outputs, diagnostics, err := jack.Compile(context.Background(), readers, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return outputs, diagnostics
+}
+
+// Compiles statements as the body of the function Main.main, and returns the diagnostics along with the text
+// of the source each of them underlines
+func compileMain(t *testing.T, statements string, opts jack.Options) ([]jack.Diagnostic, []string) {
+	t.Helper()
+	source := "class Main { function void main() { " + statements + " } }"
+	_, diagnostics := compileSources(t, map[string]string{"Main.jack": source}, opts)
+	underlined := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		underlined[i] = source[d.Range.Start.Offset:d.Range.End.Offset]
+	}
+	return diagnostics, underlined
+}
@@ -0,0 +1,75 @@
+package jack
+
+// Severities of a diagnostic
+const (
+	SEVERITY_ERROR   = "error"
+	SEVERITY_WARNING = "warning"
+)
+
+// Diagnostic codes, they identify the kind of problem independently of the wording of the message.
+// J01xx are lexical errors, J02xx syntax errors, J03xx problems found while generating code,
+// J04xx names that are not declared or declared twice, J05xx calls that don't match the declaration of the subroutine
+// and J06xx type mismatches.
+const (
+	CODE_ILLEGAL_CHARACTER          = "J0101"
+	CODE_UNTERMINATED_STRING        = "J0102"
+	CODE_UNTERMINATED_COMMENT       = "J0103"
+	CODE_INVALID_INTEGER            = "J0104"
+	CODE_INVALID_ESCAPE             = "J0105"
+	CODE_INVALID_CHARACTER_CONSTANT = "J0106"
+
+	CODE_SYNTAX = "J0201"
+
+	CODE_NON_HACK_CHARACTER = "J0301"
+
+	CODE_UNDECLARED_VARIABLE  = "J0401"
+	CODE_UNKNOWN_CLASS        = "J0402"
+	CODE_UNKNOWN_SUBROUTINE   = "J0403"
+	CODE_FIELD_IN_FUNCTION    = "J0404"
+	CODE_NOT_AN_OBJECT        = "J0405"
+	CODE_DUPLICATE_VARIABLE   = "J0406"
+	CODE_DUPLICATE_SUBROUTINE = "J0407"
+	CODE_DUPLICATE_CLASS      = "J0408"
+
+	CODE_ARGUMENT_COUNT        = "J0501"
+	CODE_METHOD_WITHOUT_OBJECT = "J0502"
+	CODE_FUNCTION_ON_OBJECT    = "J0503"
+
+	CODE_INCOMPATIBLE_TYPES = "J0601"
+	CODE_OPERAND_TYPE       = "J0602"
+	CODE_CONDITION_TYPE     = "J0603"
+	CODE_RETURN_TYPE        = "J0604"
+)
+
+// Source text from Start up to, but not including, End
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Another location that helps explaining a diagnostic, such as a previous declaration
+type RelatedLocation struct {
+	Message string `json:"message"`
+	File    string `json:"file"`
+	Range   Range  `json:"range"`
+}
+
+// An error or warning about a source file
+type Diagnostic struct {
+	Severity string            `json:"severity"` // SEVERITY_ERROR or SEVERITY_WARNING
+	Code     string            `json:"code"`     // one of the CODE_ constants
+	Message  string            `json:"message"`
+	File     string            `json:"file"`
+	Range    Range             `json:"range"`
+	Label    string            `json:"label,omitempty"` // short explanation shown under the range in the rendered text
+	Hints    []string          `json:"hints,omitempty"` // suggestions on how to fix the problem
+	Related  []RelatedLocation `json:"related,omitempty"`
+}
+
+// Formats the diagnostic as File.jack:line:column: msg, with "warning: " before the message of warnings
+func (d Diagnostic) String() string {
+	if d.Severity == SEVERITY_WARNING {
+		return d.Range.Start.String() + ": warning: " + d.Message
+	}
+	return d.Range.Start.String() + ": " + d.Message
+}
@@ -0,0 +1,252 @@
+package jack
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Output formats of the diagnostics
+const (
+	DIAGNOSTICS_TEXT  = "text"  // diagnostics rendered with their source line, written as soon as a file is compiled
+	DIAGNOSTICS_JSON  = "json"  // a JSON array of all the diagnostics, written on Close
+	DIAGNOSTICS_SARIF = "sarif" // a SARIF 2.1.0 log of all the diagnostics, written on Close
+)
+
+const SARIF_SCHEMA = "https://json.schemastore.org/sarif-2.1.0.json"
+
+func IsDiagnosticsFormat(format string) bool {
+	return format == DIAGNOSTICS_TEXT || format == DIAGNOSTICS_JSON || format == DIAGNOSTICS_SARIF
+}
+
+// Writes the diagnostics of every compiled file in one of the DIAGNOSTICS_ formats
+type DiagnosticWriter struct {
+	output      io.Writer
+	format      string
+	diagnostics []Diagnostic
+}
+
+func CreateDiagnosticWriter(output io.Writer, format string) *DiagnosticWriter {
+	dw := &DiagnosticWriter{output: output, format: format, diagnostics: make([]Diagnostic, 0)}
+	return dw
+}
+
+// Writes the diagnostics of a file, source is the content of the file, for the text format
+// to show the lines of the diagnostics. It may be nil if the content isn't available.
+func (dw *DiagnosticWriter) Write(diagnostics []Diagnostic, source []byte) {
+	if dw.format == DIAGNOSTICS_TEXT {
+		lines := strings.Split(string(source), "\n")
+		if source == nil {
+			lines = nil
+		}
+		for _, d := range diagnostics {
+			io.WriteString(dw.output, renderDiagnostic(d, lines)+"\n")
+		}
+		return
+	}
+	dw.diagnostics = append(dw.diagnostics, diagnostics...)
+}
+
+// Renders a diagnostic with the source line it refers to and the range underlined, followed by its hints and related locations:
+//
+//	error[J0201]: expected ';', found 'let'
+//	 --> Main.jack:6:18
+//	  |
+//	6 |         var int b
+//	  |                  ^ expected ';'
+func renderDiagnostic(d Diagnostic, lines []string) string {
+	gutter := strings.Repeat(" ", len(strconv.Itoa(d.Range.Start.Line)))
+	for _, related := range d.Related {
+		if width := len(strconv.Itoa(related.Range.Start.Line)); width > len(gutter) {
+			gutter = strings.Repeat(" ", width)
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(d.Severity + "[" + d.Code + "]: " + d.Message + "\n")
+	renderSnippet(&sb, gutter, d.Range, lines, '^', d.Label)
+	for _, hint := range d.Hints {
+		sb.WriteString(gutter + " = help: " + hint + "\n")
+	}
+	for _, related := range d.Related {
+		sb.WriteString("note: " + related.Message + "\n")
+		if related.File == d.File {
+			renderSnippet(&sb, gutter, related.Range, lines, '-', "")
+		} else {
+			renderSnippet(&sb, gutter, related.Range, nil, '-', "")
+		}
+	}
+	return sb.String()
+}
+
+// Writes the location of rng and, if lines has it, its first line with the range underlined by marker characters
+func renderSnippet(sb *strings.Builder, gutter string, rng Range, lines []string, marker byte, label string) {
+	start := rng.Start
+	sb.WriteString(gutter + "--> " + start.String() + "\n")
+	if start.Line < 1 || start.Line > len(lines) {
+		return
+	}
+	line := strings.TrimSuffix(lines[start.Line-1], "\r")
+	column := start.Column - 1
+	if column > len(line) {
+		column = len(line)
+	}
+	end := len(line) // a range over several lines is underlined up to the end of its first line
+	if rng.End.Line == start.Line {
+		end = rng.End.Column - 1
+	}
+	if end > len(line) {
+		end = len(line)
+	}
+	width := utf8.RuneCountInString(line[column:maxInt(column, end)])
+	if width == 0 {
+		width = 1
+	}
+
+	// the line keeps its tabs, so the underline is indented with the same tabs to stay aligned
+	var indent strings.Builder
+	for _, ch := range line[:column] {
+		if ch == '\t' {
+			indent.WriteByte('\t')
+		} else {
+			indent.WriteByte(' ')
+		}
+	}
+	lineNumber := strconv.Itoa(start.Line)
+	sb.WriteString(gutter + " |\n")
+	sb.WriteString(lineNumber + strings.Repeat(" ", len(gutter)-len(lineNumber)) + " | " + line + "\n")
+	underline := indent.String() + strings.Repeat(string(marker), width)
+	if label != "" {
+		underline += " " + label
+	}
+	sb.WriteString(gutter + " | " + underline + "\n")
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Writes the diagnostics collected in the JSON and SARIF formats
+func (dw *DiagnosticWriter) Close() {
+	var log interface{}
+	switch dw.format {
+	case DIAGNOSTICS_JSON:
+		{
+			log = dw.diagnostics
+		}
+	case DIAGNOSTICS_SARIF:
+		{
+			log = dw.sarifLog()
+		}
+	default:
+		{
+			return
+		}
+	}
+	encoder := json.NewEncoder(dw.output)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(log)
+}
+
+// The subset of SARIF used for the diagnostics
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+func (dw *DiagnosticWriter) sarifLog() sarifLog {
+	results := make([]sarifResult, 0, len(dw.diagnostics))
+	ruleIDs := make(map[string]bool)
+	for _, d := range dw.diagnostics {
+		result := sarifResult{
+			RuleID:    d.Code,
+			Level:     d.Severity,
+			Message:   sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocationOf(d.File, d.Range)}},
+		}
+		for i, related := range d.Related {
+			result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+				ID:               i + 1,
+				PhysicalLocation: sarifPhysicalLocationOf(related.File, related.Range),
+				Message:          &sarifMessage{Text: related.Message},
+			})
+		}
+		results = append(results, result)
+		ruleIDs[d.Code] = true
+	}
+	rules := make([]sarifRule, 0, len(ruleIDs))
+	for id := range ruleIDs {
+		rules = append(rules, sarifRule{ID: id})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	driver := sarifDriver{Name: "JackAnalyzer", Rules: rules}
+	return sarifLog{Version: "2.1.0", Schema: SARIF_SCHEMA, Runs: []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}}}
+}
+
+func sarifPhysicalLocationOf(file string, rng Range) sarifPhysicalLocation {
+	region := sarifRegion{StartLine: rng.Start.Line, StartColumn: rng.Start.Column, EndLine: rng.End.Line, EndColumn: rng.End.Column}
+	if region.EndLine < region.StartLine || (region.EndLine == region.StartLine && region.EndColumn <= region.StartColumn) {
+		// empty range, the region is the start character
+		region.EndLine, region.EndColumn = region.StartLine, region.StartColumn+1
+	}
+	return sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: file}, Region: region}
+}
@@ -0,0 +1,55 @@
+package jack
+
+// Collects the errors and warnings of the compilation of a file as diagnostics.
+// The CompilationEngine and the CodeGenerator of a file share one.
+type ErrorReporter struct {
+	diagnostics []Diagnostic
+	errorCount  int
+}
+
+func CreateErrorReporter() *ErrorReporter {
+	return &ErrorReporter{}
+}
+
+func (r *ErrorReporter) Error(code string, rng Range, msg string) {
+	r.Report(Diagnostic{Severity: SEVERITY_ERROR, Code: code, Message: msg, File: rng.Start.File, Range: rng})
+}
+
+func (r *ErrorReporter) Warning(code string, rng Range, msg string) {
+	r.Report(Diagnostic{Severity: SEVERITY_WARNING, Code: code, Message: msg, File: rng.Start.File, Range: rng})
+}
+
+// Reports a name declared at rng that was already declared at first
+func (r *ErrorReporter) Redeclaration(code string, rng Range, msg string, first Range) {
+	r.Report(Diagnostic{
+		Severity: SEVERITY_ERROR,
+		Code:     code,
+		Message:  msg,
+		File:     rng.Start.File,
+		Range:    rng,
+		Label:    "already declared",
+		Related:  []RelatedLocation{{Message: "first declaration", File: first.Start.File, Range: first}},
+	})
+}
+
+// Reports an error found by the tokenizer
+func (r *ErrorReporter) LexicalError(err LexicalError) {
+	r.Error(err.Code, Range{Start: err.Pos, End: err.End}, err.Msg)
+}
+
+func (r *ErrorReporter) Report(d Diagnostic) {
+	r.diagnostics = append(r.diagnostics, d)
+	if d.Severity == SEVERITY_ERROR {
+		r.errorCount++
+	}
+}
+
+// Returns the diagnostics reported so far, in the order they were reported
+func (r *ErrorReporter) Diagnostics() []Diagnostic {
+	return r.diagnostics
+}
+
+// Returns the number of errors reported so far
+func (r *ErrorReporter) ErrorCount() int {
+	return r.errorCount
+}
@@ -0,0 +1,105 @@
+package jack
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// Character codes of the Hack character set that differ from their ASCII counterparts
+const (
+	HACK_NEWLINE   = 128
+	HACK_BACKSPACE = 129
+)
+
+// Ranges of the codes in the Hack character set
+const (
+	HACK_FIRST_PRINTABLE = 32  // space
+	HACK_LAST_PRINTABLE  = 126 // ~
+	HACK_LAST_KEY        = 152 // F12, keys start at HACK_NEWLINE
+)
+
+// How characters outside the Hack character set are handled in string constants
+const (
+	CHARSET_ERROR         = "error"         // report an error
+	CHARSET_WARN          = "warn"          // warn and replace them with CHARSET_REPLACEMENT
+	CHARSET_TRANSLITERATE = "transliterate" // replace them with similar ASCII characters, warn if there are none
+)
+
+const CHARSET_REPLACEMENT = '?'
+
+// The Hack character set: every character of a string constant that has a code in it.
+// Printable ASCII characters keep their codes, '\n' and '\b' are the newline and backspace keys,
+// and the other keys (left arrow .. F12) can be written as the escapes \x82 .. \x98.
+var hackCharset map[rune]int
+
+// ASCII replacements for common characters that are not in the Hack character set
+var asciiTransliterations = map[rune]string{
+	'\t': " ", '\u00a0': " ", '\u2002': " ", '\u2003': " ", '\u2009': " ", // tab and unicode spaces
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "--", '−': "-",
+	'‘': "'", '’': "'", '‚': "'", '′': "'",
+	'“': "\"", '”': "\"", '„': "\"", '«': "\"", '»': "\"", '″': "\"",
+	'…': "...", '•': "*", '·': ".", '×': "x", '÷': "/",
+	'©': "(c)", '®': "(r)", '™': "(tm)", '°': "deg",
+	'←': "<-", '→': "->", '≤': "<=", '≥': ">=", '≠': "!=",
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE",
+	'Ç': "C", 'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ð': "D", 'Ñ': "N",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ý': "Y", 'Þ': "Th", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ð': "d", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'þ': "th", 'ÿ': "y",
+	'Œ': "OE", 'œ': "oe",
+}
+
+func init() {
+	hackCharset = make(map[rune]int)
+	for code := 0; code <= HACK_LAST_KEY; code++ {
+		if IsHackCode(code) {
+			hackCharset[rune(code)] = code
+		}
+	}
+	hackCharset['\n'] = HACK_NEWLINE
+	hackCharset['\b'] = HACK_BACKSPACE
+}
+
+// Returns the Hack character set code of a character of a string or character constant,
+// ok is false if the character is not in the Hack character set
+func HackCharCode(ch rune) (int, bool) {
+	code, ok := hackCharset[ch]
+	return code, ok
+}
+
+// Returns whether code is a printable character or a key of the Hack character set
+func IsHackCode(code int) bool {
+	return (code >= HACK_FIRST_PRINTABLE && code <= HACK_LAST_PRINTABLE) || (code >= HACK_NEWLINE && code <= HACK_LAST_KEY)
+}
+
+// Returns whether policy is one of the CHARSET_ policies
+func IsCharsetPolicy(policy string) bool {
+	return policy == CHARSET_ERROR || policy == CHARSET_WARN || policy == CHARSET_TRANSLITERATE
+}
+
+// Describes a character in messages, e.g. 'é' (U+00E9)
+func describeRune(ch rune) string {
+	if ch == utf8.RuneError {
+		return "an invalid UTF-8 byte"
+	}
+	hex := strconv.FormatInt(int64(ch), 16)
+	for len(hex) < 4 {
+		hex = "0" + hex
+	}
+	return strconv.QuoteRune(ch) + " (U+" + toUpper(hex) + ")"
+}
+
+func toUpper(s string) string {
+	res := []byte(s)
+	for i, c := range res {
+		if c >= 'a' && c <= 'z' {
+			res[i] = c - 'a' + 'A'
+		}
+	}
+	return string(res)
+}
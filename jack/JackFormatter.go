@@ -0,0 +1,269 @@
+package jack
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Indentation of one nesting level in formatted code
+const FORMAT_INDENT = "    "
+
+// Rewrites a class in the canonical Jack style: one statement or declaration per line, indented by FORMAT_INDENT
+// per level of braces, opening braces at the end of the line, one space around binary operators and after commas,
+// at most one blank line in a row. Comments are kept where they are, relative to the tokens around them.
+//
+// The formatter works on the tokens of the class, the source must have been parsed without errors before.
+type JackFormatter struct {
+	source         []byte // the tokens are written as they appear in it
+	out            bytes.Buffer
+	oneDeclPerLine bool
+	depth          int   // nesting level of braces
+	prev           Token // last token written, of type NO_TOKEN at the start
+	prevUnary      bool  // prev is a unary operator
+	lastLine       int   // line of the source where the last token or comment written ends
+	atLineStart    bool
+	newlinePending bool   // the next token goes on a new line
+	continuation   bool   // the current line continues a statement broken by a comment
+	declPrefix     string // kind and type of the declaration being written, "" outside declarations
+	declType       Token
+	declSplit      bool // a declaration was ended at a comma, the next token starts a new one
+}
+
+func CreateJackFormatter(source []byte) *JackFormatter {
+	f := &JackFormatter{source: source, atLineStart: true}
+	return f
+}
+
+// Splits declarations of several variables such as "var int x, y;" into one declaration per variable
+func (f *JackFormatter) SplitDeclarations() {
+	f.oneDeclPerLine = true
+}
+
+// Returns the formatted class made of tokens, followed by the trailing comments of the file
+func (f *JackFormatter) Format(tokens []Token, trailing []Comment) []byte {
+	for _, token := range tokens {
+		f.writeToken(token)
+	}
+	for _, comment := range trailing {
+		f.writeComment(comment)
+	}
+	if f.out.Len() > 0 {
+		f.out.WriteString("\n")
+	}
+	return f.out.Bytes()
+}
+
+func (f *JackFormatter) writeToken(token Token) {
+	for _, comment := range token.Comments {
+		f.writeComment(comment)
+	}
+	if f.oneDeclPerLine && f.declPrefix != "" && token.Type == SYMBOL && token.Text == "," {
+		// end the declaration here, the comments on the line of the comma stay at the end of it
+		f.out.WriteString(";")
+		token.Text = ";"
+		f.prev, f.prevUnary, f.lastLine = token, false, token.End.Line
+		f.declSplit = true
+		return
+	}
+	if f.declSplit {
+		// start a new declaration for the next variable
+		f.declSplit = false
+		f.continuation = false
+		f.newline(false)
+		f.writeIndent()
+		f.out.WriteString(f.declPrefix)
+		f.atLineStart = false
+		f.prev, f.prevUnary = f.declType, false
+	}
+
+	if token.Type == KEYWORD && token.Text == "else" && f.isPrev(SYMBOL, "}") && len(token.Comments) == 0 {
+		f.newlinePending = false // } else {
+	}
+	if f.newlinePending {
+		blank := token.Pos.Line-f.lastLine > 1 && !f.isPrev(SYMBOL, "{") && !(token.Type == SYMBOL && token.Text == "}")
+		f.newline(blank)
+	}
+	if token.Type == SYMBOL && token.Text == "}" {
+		f.depth--
+	}
+	unary := token.Type == SYMBOL && (token.Text == "~" || (token.Text == "-" && !f.prevEndsOperand()))
+	if f.atLineStart {
+		f.writeIndent()
+	} else if f.needsSpace(token) {
+		f.out.WriteString(" ")
+	}
+	f.out.Write(f.source[token.Pos.Offset:token.End.Offset])
+	f.atLineStart = false
+
+	statementStart := f.atStatementStart()
+	f.prev, f.prevUnary, f.lastLine = token, unary, token.End.Line
+	switch {
+	case token.Type == KEYWORD && statementStart && (token.Text == "var" || token.Text == "field" || token.Text == "static"):
+		{
+			f.declPrefix = token.Text
+		}
+	case f.declPrefix != "" && f.declType.Type == NO_TOKEN:
+		{
+			f.declPrefix += " " + string(f.source[token.Pos.Offset:token.End.Offset])
+			f.declType = token
+		}
+	}
+	if token.Type == SYMBOL && (token.Text == ";" || token.Text == "{" || token.Text == "}") {
+		if token.Text == "{" {
+			f.depth++
+		}
+		f.newlinePending = true
+		f.continuation = false
+		f.declPrefix, f.declType = "", Token{}
+	}
+}
+
+// Writes a comment at the end of the current line if it started on the line of the previous token,
+// or on a line of its own otherwise
+func (f *JackFormatter) writeComment(comment Comment) {
+	text := strings.TrimRight(comment.Text, " \t\r")
+	if f.prev.Type != NO_TOKEN && comment.Pos.Line == f.lastLine {
+		f.out.WriteString(" " + text)
+	} else {
+		if f.out.Len() > 0 {
+			f.newline(comment.Pos.Line-f.lastLine > 1 && !f.isPrev(SYMBOL, "{"))
+		}
+		f.continuation = !f.atStatementStart()
+		f.writeIndent()
+		f.out.WriteString(f.reindent(text, comment.Pos.Column-1))
+		f.atLineStart = false
+		f.newlinePending = true
+	}
+	if strings.HasPrefix(text, "//") && !f.newlinePending {
+		// the rest of the statement goes on the next line
+		f.newlinePending = true
+		f.continuation = true
+	}
+	f.lastLine = comment.End.Line
+}
+
+// Moves the lines of a block comment after the first one along with it, from column oldIndent to the current indentation
+func (f *JackFormatter) reindent(text string, oldIndent int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return text
+	}
+	indent := f.indent()
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t\r")
+		if len(line) >= oldIndent && strings.TrimLeft(line[:oldIndent], " \t") == "" {
+			line = line[oldIndent:]
+		} else {
+			line = strings.TrimLeft(line, " \t")
+			if strings.HasPrefix(line, "*") {
+				line = " " + line
+			}
+		}
+		if line != "" {
+			line = indent + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (f *JackFormatter) newline(blank bool) {
+	f.out.WriteString("\n")
+	if blank {
+		f.out.WriteString("\n")
+	}
+	f.atLineStart = true
+	f.newlinePending = false
+}
+
+func (f *JackFormatter) writeIndent() {
+	f.out.WriteString(f.indent())
+}
+
+func (f *JackFormatter) indent() string {
+	depth := f.depth
+	if f.continuation {
+		depth++
+	}
+	if depth < 0 {
+		depth = 0
+	}
+	return strings.Repeat(FORMAT_INDENT, depth)
+}
+
+// Tells whether a space separates the previous token from token, when they are on the same line
+func (f *JackFormatter) needsSpace(token Token) bool {
+	if f.prevUnary || f.isPrev(SYMBOL, "(") || f.isPrev(SYMBOL, "[") || f.isPrev(SYMBOL, ".") {
+		return false
+	}
+	if token.Type == SYMBOL {
+		switch token.Text {
+		case ";", ",", ")", "]", ".":
+			{
+				return false
+			}
+		case "(", "[": // no space in calls and array accesses, but one after if and while
+			{
+				return f.prev.Type != IDENTIFIER
+			}
+		}
+	}
+	return true
+}
+
+// Tells whether the previous token can end an operand, in which case a following '-' is a binary operator
+func (f *JackFormatter) prevEndsOperand() bool {
+	switch f.prev.Type {
+	case IDENTIFIER, INT_CONST, STRING_CONST, CHAR_CONST:
+		{
+			return true
+		}
+	case KEYWORD:
+		{
+			return f.prev.Text == "this" || f.prev.Text == "true" || f.prev.Text == "false" || f.prev.Text == "null"
+		}
+	case SYMBOL:
+		{
+			return f.prev.Text == ")" || f.prev.Text == "]"
+		}
+	}
+	return false
+}
+
+// Tells whether the next token starts a statement or declaration
+func (f *JackFormatter) atStatementStart() bool {
+	return f.prev.Type == NO_TOKEN || f.isPrev(SYMBOL, ";") || f.isPrev(SYMBOL, "{") || f.isPrev(SYMBOL, "}")
+}
+
+func (f *JackFormatter) isPrev(tokenType TokenType, text string) bool {
+	return f.prev.Type == tokenType && f.prev.Text == text
+}
+
+// Formats the class in source, fileName is only used in the diagnostics. The class is parsed first:
+// if it has errors they are reported and nil is returned.
+func FormatSource(fileName string, source []byte, escapes bool, splitDeclarations bool, reporter *ErrorReporter) []byte {
+	cEngine := CreateCompilationEngineFromReader(fileName, bytes.NewReader(source), reporter)
+	if escapes {
+		cEngine.EnableEscapes()
+	}
+	cEngine.CompileClass()
+	if reporter.ErrorCount() > 0 {
+		return nil
+	}
+
+	jt := CreateTokenizerFromReader(fileName, bytes.NewReader(source))
+	if escapes {
+		jt.EnableEscapes()
+	}
+	jt.KeepComments()
+	tokens := make([]Token, 0)
+	for jt.HasMoreTokens() {
+		tokens = append(tokens, jt.Peek(0))
+		jt.Advance()
+	}
+	f := CreateJackFormatter(source)
+	if splitDeclarations {
+		f.SplitDeclarations()
+	}
+	return f.Format(tokens, jt.TrailingComments())
+}
@@ -0,0 +1,38 @@
+package jack_test
+
+import (
+	"testing"
+
+	"compiler/jack"
+)
+
+func TestFormatSplitDeclarations(t *testing.T) {
+	const source = `class D {
+  field int a, // first
+    b, /* second */ c;
+  field int u,
+    // own line
+    v;
+}
+`
+	const want = `class D {
+    field int a; // first
+    field int b; /* second */
+    field int c;
+    field int u;
+    // own line
+    field int v;
+}
+`
+	reporter := jack.CreateErrorReporter()
+	got := jack.FormatSource("D.jack", []byte(source), false, true, reporter)
+	if reporter.ErrorCount() != 0 {
+		t.Fatal(reporter.Diagnostics())
+	}
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if again := jack.FormatSource("D.jack", got, false, true, reporter); string(again) != want {
+		t.Errorf("formatting again gives\n%s", again)
+	}
+}
@@ -0,0 +1,743 @@
+package jack
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Kinds of tokens
+type TokenType int
+
+const (
+	NO_TOKEN TokenType = iota // past the end of the input
+	KEYWORD
+	SYMBOL
+	IDENTIFIER
+	INT_CONST
+	STRING_CONST
+	CHAR_CONST // extension: 'A' stands for the Hack code of the character
+)
+
+// Names of the token types, as used in the course's XML files
+var tokenTypeNames = [...]string{"", "keyword", "symbol", "identifier", "integerConstant", "stringConstant", "charConstant"}
+
+func (t TokenType) String() string {
+	return tokenTypeNames[t]
+}
+
+/*// Keyword constants
+const CLASS = 5
+const METHOD = 6
+const FUNCTION = 7
+const CONSTRUCTOR = 8
+const INT = 9
+const BOOLEAN = 10
+const CHAR = 11
+const VOID = 12
+const VAR = 13
+const STATIC = 14
+const FIELD = 15
+const LET = 16
+const DO = 17
+const IF = 18
+const ELSE = 19
+const WHILE = 20
+const RETURN = 21
+const TRUE = 22
+const FALSE = 23
+const NULL = 24
+const THIS = 25*/
+
+var keywords = []string{"class", "method", "function", "constructor", "int", "boolean", "char", "void", "var", "static",
+	"field", "let", "do", "if", "else", "while", "return", "true", "false", "null", "this"}
+
+const symbols = "{}()[].,;+-*/&|<>=~"
+
+// Character classes, a byte may belong to several of them
+const (
+	classSpace = 1 << iota
+	classDigit
+	classHexDigit
+	classBinaryDigit
+	classIdentifierStart
+	classIdentifierChar
+)
+
+var keywordTable map[string]string // every keyword mapped to itself, so keyword tokens share their text
+var symbolText [256]string         // the text of each symbol, "" for characters that are not symbols
+var charClass [256]uint8
+
+func init() {
+	keywordTable = make(map[string]string)
+	for _, keyword := range keywords {
+		keywordTable[keyword] = keyword
+	}
+	for i := 0; i < len(symbols); i++ {
+		symbolText[symbols[i]] = symbols[i : i+1]
+	}
+	for _, c := range []byte(" \t\n\r\f\v") {
+		charClass[c] |= classSpace
+	}
+	for c := 0; c < 256; c++ {
+		isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		isDigit := c >= '0' && c <= '9'
+		if isDigit {
+			charClass[c] |= classDigit | classHexDigit | classIdentifierChar
+		}
+		if (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			charClass[c] |= classHexDigit
+		}
+		// An identifier is a sequence of letters, digits and underscores not starting with a digit
+		if isLetter || c == '_' {
+			charClass[c] |= classIdentifierStart | classIdentifierChar
+		}
+	}
+	charClass['0'] |= classBinaryDigit
+	charClass['1'] |= classBinaryDigit
+}
+
+// Position of a token inside its source file.
+// Line and Column start at 1, Offset is the byte offset from the start of the file.
+type Position struct {
+	File   string `json:"-"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Offset int    `json:"offset"`
+}
+
+func (p Position) String() string {
+	return p.File + ":" + strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+type Token struct {
+	Type   TokenType
+	Text   string // keyword, symbol, identifier, string constant or character, or the decimal value of an integer constant
+	Symbol byte
+	IntVal int
+	Pos    Position
+	End    Position // position just after the last character of the token
+	// Comments between the previous token and this one, only recorded after KeepComments
+	Comments []Comment
+}
+
+// A comment kept as trivia of the token that follows it
+type Comment struct {
+	Text string // the whole comment, including its // or /* */ delimiters
+	Pos  Position
+	End  Position
+}
+
+// A problem found while scanning the source, the tokenizer records it and keeps scanning
+type LexicalError struct {
+	Pos  Position
+	End  Position
+	Code string // one of the CODE_ diagnostic codes
+	Msg  string
+}
+
+func (e LexicalError) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// Largest value of a decimal integer constant
+const MAX_INT = 32767
+
+// Largest value of a hexadecimal or binary integer constant, which may set any bit of the Hack word
+const MAX_WORD = 0xFFFF
+
+// Size of the reads from the input, the window grows beyond it only for longer lexemes
+const readSize = 4096
+
+// Lexer states
+const (
+	stateCode         = iota // between tokens
+	stateLineComment         // inside a // comment
+	stateBlockComment        // inside a /* */ or /** */ comment
+	stateString              // inside a string constant
+)
+
+// Tokens are scanned lazily from the reader, only a window of the input, the current token
+// and the tokens requested through Peek are kept in memory.
+type JackTokenizer struct {
+	input     io.Reader
+	fileName  string
+	window    []byte // buffered input, window[next:] has not been consumed yet
+	next      int
+	mark      int  // start of the lexeme being read in window, kept when the window is refilled, -1 if none
+	readDone  bool // the input has no more bytes
+	offset    int  // byte offset of the next character to read
+	line      int
+	column    int
+	lookahead []Token // tokens scanned but not consumed, lookahead[head] is the current token
+	head      int
+	eof       bool              // no more tokens can be scanned from the input
+	err       error             // first read error other than io.EOF
+	buf       []byte            // characters of the string or character constant being scanned
+	names     map[string]string // interned identifiers and integer texts
+	errors    []LexicalError
+	escapes   bool // whether backslash escapes are recognized in string constants
+	// comments are only recorded after KeepComments, the ones after the last token returned are pending
+	keepComments bool
+	comments     []Comment
+	commentBuf   []byte
+}
+
+func CreateTokenizer(inputFile *os.File) *JackTokenizer {
+	return CreateTokenizerFromReader(filepath.Base(inputFile.Name()), inputFile)
+}
+
+// Creates a tokenizer over any reader, fileName is only used for the positions of the tokens
+func CreateTokenizerFromReader(fileName string, input io.Reader) *JackTokenizer {
+	jt := &JackTokenizer{input: input, fileName: fileName, mark: -1, line: 1, column: 1, names: make(map[string]string)}
+	return jt
+}
+
+// Turns on the escape sequences \" \\ \n \b and \xNN inside string constants.
+// Without it a backslash is an ordinary character, as in the Jack specification.
+func (jt *JackTokenizer) EnableEscapes() {
+	jt.escapes = true
+}
+
+// Makes the tokenizer record the comments, as the Comments of the token that follows them.
+// The comments after the last token are returned by TrailingComments.
+func (jt *JackTokenizer) KeepComments() {
+	jt.keepComments = true
+}
+
+// Returns the comments after the last token, once all the tokens were scanned
+func (jt *JackTokenizer) TrailingComments() []Comment {
+	if !jt.eof {
+		return nil
+	}
+	return jt.comments
+}
+
+// Scans the next token one character at a time.
+// Comments may start and end anywhere (including in the middle of a line or across lines)
+// and are only recognized outside of string constants.
+func (jt *JackTokenizer) scanToken() (Token, bool) {
+	state := stateCode
+	var tokenPos Position
+	for {
+		c, ok := jt.peekByte(0)
+		if !ok {
+			switch state {
+			case stateString:
+				{
+					jt.lexicalError(tokenPos, CODE_UNTERMINATED_STRING, "unterminated string constant")
+					return Token{Type: STRING_CONST, Text: string(jt.buf), Pos: tokenPos}, true
+				}
+			case stateBlockComment:
+				{
+					jt.lexicalError(tokenPos, CODE_UNTERMINATED_COMMENT, "unterminated comment")
+					jt.addComment(tokenPos)
+				}
+			case stateLineComment:
+				{
+					jt.addComment(tokenPos)
+				}
+			}
+			return Token{}, false
+		}
+		switch state {
+		case stateCode:
+			{
+				switch {
+				case charClass[c]&classSpace != 0:
+					{
+						jt.nextByte()
+					}
+				case charClass[c]&classIdentifierStart != 0: // KeyWord or Identifier
+					{
+						pos := jt.position()
+						word := jt.readWhile(classIdentifierChar)
+						if keyword, ok := keywordTable[string(word)]; ok { //KeyWord
+							return Token{Type: KEYWORD, Text: keyword, Pos: pos}, true
+						}
+						return Token{Type: IDENTIFIER, Text: jt.intern(word), Pos: pos}, true // Identifier
+					}
+				case c == '/':
+					{
+						next, _ := jt.peekByte(1)
+						if next == '/' {
+							state = stateLineComment
+						} else if next == '*' {
+							state = stateBlockComment
+						} else {
+							token := Token{Type: SYMBOL, Text: symbolText[c], Symbol: c, Pos: jt.position()}
+							jt.nextByte()
+							return token, true
+						}
+						tokenPos = jt.position()
+						jt.commentBuf = append(jt.commentBuf[:0], c, next)
+						jt.nextByte()
+						jt.nextByte()
+					}
+				case symbolText[c] != "": //Symbol
+					{
+						token := Token{Type: SYMBOL, Text: symbolText[c], Symbol: c, Pos: jt.position()}
+						jt.nextByte()
+						return token, true
+					}
+				case charClass[c]&classDigit != 0: // IntegerConstant
+					{
+						if next, _ := jt.peekByte(1); c == '0' && (next == 'x' || next == 'X' || next == 'b' || next == 'B') {
+							return jt.scanRadixInt(), true
+						}
+						pos := jt.position()
+						digits := jt.readWhile(classDigit)
+						num := 0
+						for _, d := range digits {
+							num = num*10 + int(d-'0')
+							if num > MAX_INT {
+								jt.lexicalError(pos, CODE_INVALID_INTEGER, "integer constant "+string(digits)+" is out of range 0.."+strconv.Itoa(MAX_INT))
+								num = 0
+								break
+							}
+						}
+						return Token{Type: INT_CONST, Text: jt.intText(num), IntVal: num, Pos: pos}, true
+					}
+				case c == '"': // StringConstant
+					{
+						state = stateString
+						tokenPos = jt.position()
+						jt.buf = jt.buf[:0]
+						jt.nextByte()
+					}
+				case c == '\'': // CharConstant
+					{
+						return jt.scanChar(), true
+					}
+				default:
+					{
+						jt.illegalCharacter()
+					}
+				}
+			}
+		case stateLineComment:
+			{
+				if c == '\n' {
+					state = stateCode
+					jt.addComment(tokenPos)
+				} else if jt.keepComments {
+					jt.commentBuf = append(jt.commentBuf, c)
+				}
+				jt.nextByte()
+			}
+		case stateBlockComment:
+			{
+				if next, _ := jt.peekByte(1); c == '*' && next == '/' {
+					state = stateCode
+					jt.nextByte()
+					jt.nextByte()
+					if jt.keepComments {
+						jt.commentBuf = append(jt.commentBuf, '*', '/')
+					}
+					jt.addComment(tokenPos)
+					continue
+				}
+				if jt.keepComments {
+					jt.commentBuf = append(jt.commentBuf, c)
+				}
+				jt.nextByte()
+			}
+		case stateString:
+			{
+				if c == '\n' { // a string constant can't span lines
+					jt.lexicalError(jt.position(), CODE_UNTERMINATED_STRING, "newline in string constant")
+					return Token{Type: STRING_CONST, Text: string(jt.buf), Pos: tokenPos}, true
+				}
+				if c == '\\' && jt.escapes {
+					jt.scanEscape()
+					continue
+				}
+				jt.nextByte()
+				if c == '"' {
+					return Token{Type: STRING_CONST, Text: string(jt.buf), Pos: tokenPos}, true
+				}
+				jt.buf = append(jt.buf, c)
+			}
+		}
+	}
+}
+
+// Scans an escape sequence inside a string constant and adds the character it stands for.
+// \n and \b stand for '\n' and '\b', which are lowered to the Hack newline and backspace codes.
+// \xNN stands for the Hack code NN itself, added as the rune NN which is its own code in the Hack character set,
+// so it must be a printable character or a key. Returns false if the escape sequence is invalid.
+func (jt *JackTokenizer) scanEscape() bool {
+	pos := jt.position()
+	jt.nextByte() // backslash
+	c, ok := jt.peekByte(0)
+	if !ok || c == '\n' { // reported as an unterminated string
+		return true
+	}
+	jt.nextByte()
+	switch c {
+	case '"', '\'', '\\':
+		{
+			jt.buf = append(jt.buf, c)
+		}
+	case 'n':
+		{
+			jt.buf = append(jt.buf, '\n')
+		}
+	case 'b':
+		{
+			jt.buf = append(jt.buf, '\b')
+		}
+	case 'x':
+		{
+			code, digits := 0, 0
+			for digits < 2 {
+				h, ok := jt.peekByte(0)
+				if !ok || charClass[h]&classHexDigit == 0 {
+					break
+				}
+				code = code*16 + hexValue(h)
+				digits++
+				jt.nextByte()
+			}
+			if digits < 2 {
+				jt.lexicalError(pos, CODE_INVALID_ESCAPE, "escape sequence \\x must be followed by two hexadecimal digits")
+				return false
+			}
+			if !IsHackCode(code) {
+				jt.lexicalError(pos, CODE_INVALID_ESCAPE, "escape sequence \\x stands for code "+strconv.Itoa(code)+
+					", which is not in the Hack character set (32 to 126 and 128 to 152)")
+				return false
+			}
+			jt.buf = utf8.AppendRune(jt.buf, rune(code))
+		}
+	default:
+		{
+			jt.lexicalError(pos, CODE_INVALID_ESCAPE, "unknown escape sequence \\"+string(c))
+			return false
+		}
+	}
+	return true
+}
+
+// Scans a hexadecimal (0x4000) or binary (0b0101) integer constant
+func (jt *JackTokenizer) scanRadixInt() Token {
+	pos := jt.position()
+	jt.nextByte() // 0
+	prefix, _ := jt.peekByte(0)
+	jt.nextByte()
+	base, class := 16, uint8(classHexDigit)
+	if prefix == 'b' || prefix == 'B' {
+		base, class = 2, classBinaryDigit
+	}
+	digits := jt.readWhile(class)
+	num := 0
+	for _, d := range digits {
+		if num <= MAX_WORD { // keep going to the end of the digits, but don't overflow
+			num = num*base + hexValue(d)
+		}
+	}
+	literal := "0" + string(prefix) + string(digits)
+	if c, ok := jt.peekByte(0); ok && charClass[c]&classIdentifierChar != 0 {
+		jt.lexicalError(jt.position(), CODE_INVALID_INTEGER, "invalid digit "+strconv.QuoteRune(rune(c))+" in integer constant "+literal+string(jt.readWhile(classIdentifierChar)))
+		return Token{Type: INT_CONST, Text: jt.intText(0), Pos: pos}
+	}
+	if len(digits) == 0 {
+		jt.lexicalError(pos, CODE_INVALID_INTEGER, "integer constant "+literal+" has no digits")
+		return Token{Type: INT_CONST, Text: jt.intText(0), Pos: pos}
+	}
+	if num > MAX_WORD {
+		jt.lexicalError(pos, CODE_INVALID_INTEGER, "integer constant "+literal+" does not fit in a 16-bit Hack word")
+		num = 0
+	}
+	return Token{Type: INT_CONST, Text: jt.intText(num), IntVal: num, Pos: pos}
+}
+
+// Scans a character constant such as 'A', ' ' or '\n', escape sequences are always recognized in them
+func (jt *JackTokenizer) scanChar() Token {
+	pos := jt.position()
+	jt.nextByte() // opening quote
+	jt.buf = jt.buf[:0]
+	valid := true
+	for {
+		c, ok := jt.peekByte(0)
+		if !ok || c == '\n' {
+			jt.lexicalError(pos, CODE_INVALID_CHARACTER_CONSTANT, "unterminated character constant")
+			return Token{Type: CHAR_CONST, Pos: pos}
+		}
+		if c == '\\' {
+			valid = jt.scanEscape() && valid
+			continue
+		}
+		jt.nextByte()
+		if c == '\'' {
+			break
+		}
+		jt.buf = append(jt.buf, c)
+	}
+
+	if !valid { // already reported
+		return Token{Type: CHAR_CONST, Pos: pos}
+	}
+	ch, size := utf8.DecodeRune(jt.buf)
+	if len(jt.buf) == 0 || size != len(jt.buf) {
+		jt.lexicalError(pos, CODE_INVALID_CHARACTER_CONSTANT, "character constant must contain exactly one character")
+		return Token{Type: CHAR_CONST, Pos: pos}
+	}
+	code, ok := HackCharCode(ch)
+	if !ok {
+		jt.lexicalError(pos, CODE_INVALID_CHARACTER_CONSTANT, "character "+describeRune(ch)+" has no code in the Hack character set")
+	}
+	return Token{Type: CHAR_CONST, Text: string(jt.buf), IntVal: code, Pos: pos}
+}
+
+// Reports and skips a character that can't start any token
+func (jt *JackTokenizer) illegalCharacter() {
+	pos := jt.position()
+	jt.peekByte(utf8.UTFMax - 1)
+	r, size := utf8.DecodeRune(jt.window[jt.next:])
+	if r == utf8.RuneError && size == 1 {
+		jt.lexicalError(pos, CODE_ILLEGAL_CHARACTER, "illegal byte 0x"+strconv.FormatInt(int64(jt.window[jt.next]), 16))
+	} else {
+		jt.lexicalError(pos, CODE_ILLEGAL_CHARACTER, "illegal character "+strconv.QuoteRune(r))
+	}
+	for i := 0; i < size; i++ {
+		jt.nextByte()
+	}
+}
+
+// Records the comment that starts at pos and ends at the current character, if comments are kept
+func (jt *JackTokenizer) addComment(pos Position) {
+	if jt.keepComments {
+		jt.comments = append(jt.comments, Comment{Text: string(jt.commentBuf), Pos: pos, End: jt.position()})
+	}
+}
+
+// Records a lexical error that starts at pos and ends at the current character
+func (jt *JackTokenizer) lexicalError(pos Position, code string, msg string) {
+	end := jt.position()
+	if end.Offset < pos.Offset {
+		end = pos
+	}
+	jt.errors = append(jt.errors, LexicalError{Pos: pos, End: end, Code: code, Msg: msg})
+}
+
+// Returns the lexical errors found in the tokens scanned so far
+func (jt *JackTokenizer) Errors() []LexicalError {
+	return jt.errors
+}
+
+// Returns the character n places ahead of the current one, ok is false past the end of the input
+func (jt *JackTokenizer) peekByte(n int) (byte, bool) {
+	if jt.next+n >= len(jt.window) && !jt.fillWindow(n+1) {
+		return 0, false
+	}
+	return jt.window[jt.next+n], true
+}
+
+// Reads from the input until at least n characters are available, returns false if the input ends first.
+// Consumed characters are dropped from the window, except for the lexeme being read.
+func (jt *JackTokenizer) fillWindow(n int) bool {
+	for len(jt.window)-jt.next < n {
+		if jt.readDone {
+			return false
+		}
+		keep := jt.next
+		if jt.mark >= 0 {
+			keep = jt.mark
+		}
+		if keep > 0 {
+			remaining := copy(jt.window, jt.window[keep:])
+			jt.window = jt.window[:remaining]
+			jt.next -= keep
+			if jt.mark >= 0 {
+				jt.mark -= keep
+			}
+		}
+		if len(jt.window) == cap(jt.window) {
+			grown := make([]byte, len(jt.window), 2*cap(jt.window)+readSize)
+			copy(grown, jt.window)
+			jt.window = grown
+		}
+		count, err := jt.input.Read(jt.window[len(jt.window):cap(jt.window)])
+		jt.window = jt.window[:len(jt.window)+count]
+		if err != nil {
+			if err != io.EOF {
+				jt.err = err
+			}
+			jt.readDone = true
+		}
+	}
+	return true
+}
+
+// Consumes the current character and keeps the line and column up to date
+func (jt *JackTokenizer) nextByte() {
+	if jt.next >= len(jt.window) {
+		return
+	}
+	if jt.window[jt.next] == '\n' {
+		jt.line++
+		jt.column = 1
+	} else {
+		jt.column++
+	}
+	jt.next++
+	jt.offset++
+}
+
+// Consumes the characters of the given class that are not newlines and returns them.
+// The returned slice points into the window, so it is only valid until the next read.
+func (jt *JackTokenizer) readWhile(class uint8) []byte {
+	jt.mark = jt.next
+	for {
+		c, ok := jt.peekByte(0)
+		if !ok || charClass[c]&class == 0 {
+			break
+		}
+		jt.next++
+	}
+	lexeme := jt.window[jt.mark:jt.next]
+	jt.column += len(lexeme)
+	jt.offset += len(lexeme)
+	jt.mark = -1
+	return lexeme
+}
+
+// Returns the lexeme as a string, allocating it only the first time it is seen
+func (jt *JackTokenizer) intern(lexeme []byte) string {
+	if s, ok := jt.names[string(lexeme)]; ok {
+		return s
+	}
+	s := string(lexeme)
+	jt.names[s] = s
+	return s
+}
+
+// Returns the decimal text of an integer constant
+func (jt *JackTokenizer) intText(num int) string {
+	var digits [8]byte
+	return jt.intern(strconv.AppendInt(digits[:0], int64(num), 10))
+}
+
+func (jt *JackTokenizer) position() Position {
+	return Position{File: jt.fileName, Line: jt.line, Column: jt.column, Offset: jt.offset}
+}
+
+func hexValue(c byte) int {
+	switch {
+	case c >= 'a':
+		{
+			return int(c-'a') + 10
+		}
+	case c >= 'A':
+		{
+			return int(c-'A') + 10
+		}
+	}
+	return int(c - '0')
+}
+
+// Scans tokens until n tokens are available for lookahead or the input is exhausted
+func (jt *JackTokenizer) fill(n int) {
+	for len(jt.lookahead)-jt.head < n && !jt.eof {
+		token, ok := jt.scanToken()
+		if !ok {
+			jt.eof = true
+			break
+		}
+		token.End = jt.position()
+		if len(jt.comments) > 0 {
+			token.Comments = jt.comments
+			jt.comments = nil
+		}
+		jt.lookahead = append(jt.lookahead, token)
+	}
+}
+
+// Returns the error that stopped reading the input early, if any
+func (jt *JackTokenizer) Err() error {
+	return jt.err
+}
+
+func (jt *JackTokenizer) HasMoreTokens() bool {
+	jt.fill(1)
+	return jt.head < len(jt.lookahead)
+}
+
+// Advances to the next token
+func (jt *JackTokenizer) Advance() {
+	jt.fill(1)
+	if jt.head < len(jt.lookahead) {
+		jt.head++
+	}
+	if jt.head == len(jt.lookahead) { // reuse the lookahead buffer
+		jt.lookahead = jt.lookahead[:0]
+		jt.head = 0
+	}
+}
+
+// Returns the token n places after the current one without consuming anything,
+// Peek(0) is the current token. Past the end of the input it returns an empty token
+// positioned at the end of the file.
+func (jt *JackTokenizer) Peek(n int) Token {
+	return *jt.peekToken(n)
+}
+
+func (jt *JackTokenizer) peekToken(n int) *Token {
+	if jt.head+n >= len(jt.lookahead) {
+		jt.fill(n + 1)
+		if jt.head+n >= len(jt.lookahead) {
+			return &Token{Pos: jt.position(), End: jt.position()}
+		}
+	}
+	return &jt.lookahead[jt.head+n]
+}
+
+// Returns the text of the current token, "" if there are no more tokens
+func (jt *JackTokenizer) CurrentToken() string {
+	return jt.peekToken(0).Text
+}
+
+func (jt *JackTokenizer) TokenType() TokenType {
+	return jt.peekToken(0).Type
+}
+
+// Returns the position of the current token, or the end of the file if there are no more tokens
+func (jt *JackTokenizer) Position() Position {
+	return jt.peekToken(0).Pos
+}
+
+// Returns the position just after the current token, or the end of the file if there are no more tokens
+func (jt *JackTokenizer) End() Position {
+	return jt.peekToken(0).End
+}
+
+func (jt *JackTokenizer) KeyWord() string {
+	return jt.textOf(KEYWORD)
+}
+
+func (jt *JackTokenizer) Symbol() byte {
+	return jt.peekToken(0).Symbol
+}
+
+func (jt *JackTokenizer) Identifier() string {
+	return jt.textOf(IDENTIFIER)
+}
+
+func (jt *JackTokenizer) IntVal() int {
+	return jt.peekToken(0).IntVal
+}
+
+func (jt *JackTokenizer) StringVal() string {
+	return jt.textOf(STRING_CONST)
+}
+
+// Returns the text of the current token if it has the given type, "" otherwise
+func (jt *JackTokenizer) textOf(tokenType TokenType) string {
+	if token := jt.peekToken(0); token.Type == tokenType {
+		return token.Text
+	}
+	return ""
+}
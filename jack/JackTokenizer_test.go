@@ -0,0 +1,79 @@
+package jack_test
+
+import (
+	"strings"
+	"testing"
+
+	"compiler/jack"
+)
+
+func TestTokenizeIdentifiers(t *testing.T) {
+	type token struct {
+		Type jack.TokenType
+		Text string
+	}
+	tests := []struct {
+		input string
+		want  []token
+	}{
+		{"x1", []token{{jack.IDENTIFIER, "x1"}}},
+		{"player_2", []token{{jack.IDENTIFIER, "player_2"}}},
+		{"_tmp", []token{{jack.IDENTIFIER, "_tmp"}}},
+		{"_", []token{{jack.IDENTIFIER, "_"}}},
+		{"1abc", []token{{jack.INT_CONST, "1"}, {jack.IDENTIFIER, "abc"}}},
+		{"class_", []token{{jack.IDENTIFIER, "class_"}}},
+		{"classX", []token{{jack.IDENTIFIER, "classX"}}},
+		{"class", []token{{jack.KEYWORD, "class"}}},
+	}
+	for _, test := range tests {
+		tokens, diagnostics := jack.Tokenize("Test.jack", strings.NewReader(test.input), jack.Options{})
+		if len(diagnostics) != 0 {
+			t.Errorf("%q: unexpected diagnostics %v", test.input, diagnostics)
+		}
+		got := make([]token, len(tokens))
+		for i, tok := range tokens {
+			got[i] = token{tok.Type, tok.Text}
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("%q: got tokens %v, want %v", test.input, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%q: got tokens %v, want %v", test.input, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestTokenizeHexEscapes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // text of the string constant, "" if the escape is rejected
+	}{
+		{`"\x41"`, "A"},
+		{`"\x20\x7E"`, " ~"},
+		{`"\x80\x98"`, "\u0080\u0098"},
+		{`"\x0A"`, ""},
+		{`"\x08"`, ""},
+		{`"\x1F"`, ""},
+		{`"\x7F"`, ""},
+		{`"\x99"`, ""},
+	}
+	for _, test := range tests {
+		tokens, diagnostics := jack.Tokenize("Test.jack", strings.NewReader(test.input), jack.Options{Escapes: true})
+		if test.want == "" {
+			if len(diagnostics) != 1 || diagnostics[0].Code != jack.CODE_INVALID_ESCAPE {
+				t.Errorf("%s: got diagnostics %v, want one %s", test.input, diagnostics, jack.CODE_INVALID_ESCAPE)
+			}
+			continue
+		}
+		if len(diagnostics) != 0 {
+			t.Errorf("%s: unexpected diagnostics %v", test.input, diagnostics)
+		}
+		if len(tokens) != 1 || tokens[0].Text != test.want {
+			t.Errorf("%s: got tokens %v, want the string constant %q", test.input, tokens, test.want)
+		}
+	}
+}
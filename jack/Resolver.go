@@ -0,0 +1,347 @@
+package jack
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Checks that the names used in a class are declared: variables in the symbol tables of the class and
+// its subroutines, classes and subroutines in the ClassIndex of the program. Each name that can't be
+// resolved is reported with the closest declared name as a suggestion, so that the CodeGenerator is
+// only run on classes whose names all resolve.
+type Resolver struct {
+	index    *ClassIndex
+	reporter *ErrorReporter
+	class    *Class
+	sub      *Subroutine // subroutine being resolved
+	scope    *ClassScope
+}
+
+func CreateResolver(index *ClassIndex, reporter *ErrorReporter) *Resolver {
+	r := &Resolver{index: index, reporter: reporter}
+	return r
+}
+
+// Resolves the names of the class of scope, whose symbol tables report the variables declared twice
+func (r *Resolver) ResolveClass(scope *ClassScope) {
+	class := scope.Class
+	r.class, r.scope = class, scope
+	for _, dec := range class.VarDecs {
+		r.resolveType(dec.Type, dec.TypePos)
+	}
+	for _, sub := range class.Subroutines {
+		if first := class.Subroutine(sub.Name); first != sub {
+			r.reporter.Redeclaration(CODE_DUPLICATE_SUBROUTINE, nameRange(sub.NamePos, sub.Name),
+				"subroutine '"+sub.Name+"' is declared twice in class '"+class.Name+"'", nameRange(first.NamePos, sub.Name))
+		}
+		r.resolveSubroutine(sub)
+	}
+}
+
+func (r *Resolver) resolveSubroutine(sub *Subroutine) {
+	r.sub = sub
+	r.scope.SubroutineTable(sub) // reports the variables declared twice before the names are resolved
+	if sub.ReturnType != "void" {
+		r.resolveType(sub.ReturnType, sub.ReturnTypePos)
+	}
+	for _, param := range sub.Params {
+		r.resolveType(param.Type, param.Pos)
+	}
+	for _, dec := range sub.Locals {
+		r.resolveType(dec.Type, dec.TypePos)
+	}
+	r.resolveStatements(sub.Body)
+}
+
+func (r *Resolver) resolveStatements(statements []Statement) {
+	for _, stmt := range statements {
+		switch s := stmt.(type) {
+		case *LetStmt:
+			{
+				r.resolveVariable(s.Name, s.NamePos)
+				if s.Index != nil {
+					r.resolveExpression(s.Index)
+				}
+				r.resolveExpression(s.Value)
+			}
+		case *IfStmt:
+			{
+				r.resolveExpression(s.Cond)
+				r.resolveStatements(s.Then)
+				r.resolveStatements(s.Else)
+			}
+		case *WhileStmt:
+			{
+				r.resolveExpression(s.Cond)
+				r.resolveStatements(s.Body)
+			}
+		case *DoStmt:
+			{
+				r.resolveCall(s.Call)
+			}
+		case *ReturnStmt:
+			{
+				if s.Value != nil {
+					r.resolveExpression(s.Value)
+				}
+			}
+		}
+	}
+}
+
+func (r *Resolver) resolveExpression(expr Expression) {
+	switch e := expr.(type) {
+	case *VarRef:
+		{
+			r.resolveVariable(e.Name, e.Pos)
+		}
+	case *IndexExpr:
+		{
+			r.resolveVariable(e.Name, e.Pos)
+			r.resolveExpression(e.Index)
+		}
+	case *CallExpr:
+		{
+			r.resolveCall(e)
+		}
+	case *UnaryExpr:
+		{
+			r.resolveExpression(e.Operand)
+		}
+	case *BinaryExpr:
+		{
+			r.resolveExpression(e.Left)
+			r.resolveExpression(e.Right)
+		}
+	case *ParenExpr:
+		{
+			r.resolveExpression(e.Inner)
+		}
+	}
+}
+
+// Reports a type that is neither a primitive type nor a class
+func (r *Resolver) resolveType(sType string, pos Position) {
+	if isPrimitiveType(sType) || r.index.MayBeClass(sType) {
+		return
+	}
+	candidates := append(r.index.ClassNames(), "int", "char", "boolean")
+	r.error(CODE_UNKNOWN_CLASS, nameRange(pos, sType), "unknown type '"+sType+"'", "not a class of the program or the Jack OS",
+		didYouMean(sType, candidates)...)
+}
+
+// Returns the variable of the given name, reporting it if it isn't declared or can't be used in the subroutine.
+// ok is false if it was reported.
+func (r *Resolver) resolveVariable(name string, pos Position) (symbol Symbol, ok bool) {
+	symbol, found := r.lookup(name)
+	if !found {
+		r.error(CODE_UNDECLARED_VARIABLE, nameRange(pos, name), "undeclared variable '"+name+"'", "not declared in this subroutine or class",
+			didYouMean(name, r.variableNames())...)
+		return symbol, false
+	}
+	if symbol.Kind == FIELD && r.sub.Kind == "function" {
+		r.error(CODE_FIELD_IN_FUNCTION, nameRange(pos, name), "field '"+name+"' can't be used in a function", "fields belong to an object",
+			"functions have no object, make '"+r.sub.Name+"' a method or pass the value as an argument")
+		return symbol, false
+	}
+	return symbol, true
+}
+
+// How the subroutine of a call is reached
+const (
+	CALL_ON_THIS   = iota // name(...), on the current object for a method
+	CALL_ON_OBJECT        // varName.name(...)
+	CALL_ON_CLASS         // ClassName.name(...)
+)
+
+// Checks the receiver and the subroutine of a call, then its arguments
+func (r *Resolver) resolveCall(call *CallExpr) {
+	switch {
+	case call.Receiver == "":
+		{
+			if sub := r.class.Subroutine(call.Name); sub != nil {
+				r.checkCall(call, r.class.Name, sub, CALL_ON_THIS)
+			} else {
+				r.reportUnknownSubroutine(r.class, call)
+			}
+		}
+	case r.isVariable(call.Receiver):
+		{
+			symbol, ok := r.resolveVariable(call.Receiver, call.Pos)
+			if !ok {
+				break
+			}
+			if isPrimitiveType(symbol.Type) {
+				r.error(CODE_NOT_AN_OBJECT, nameRange(call.Pos, call.Receiver), "can't call '"+call.Name+"' on '"+call.Receiver+"' of type "+symbol.Type,
+					symbol.Type+" is not a class")
+				break
+			}
+			r.resolveSubroutineOf(symbol.Type, call, CALL_ON_OBJECT)
+		}
+	case r.index.MayBeClass(call.Receiver):
+		{
+			r.resolveSubroutineOf(call.Receiver, call, CALL_ON_CLASS)
+		}
+	default:
+		{
+			candidates := append(r.variableNames(), r.index.ClassNames()...)
+			r.error(CODE_UNKNOWN_CLASS, nameRange(call.Pos, call.Receiver), "undeclared variable or unknown class '"+call.Receiver+"'",
+				"neither a variable in scope nor a class", didYouMean(call.Receiver, candidates)...)
+		}
+	}
+	for _, arg := range call.Args {
+		r.resolveExpression(arg)
+	}
+}
+
+// Reports the subroutine of a call if the class of the index doesn't declare it, or checks the call against its declaration
+func (r *Resolver) resolveSubroutineOf(className string, call *CallExpr, callKind int) {
+	sub, ok := r.index.Subroutine(className, call.Name)
+	if !ok {
+		r.reportUnknownSubroutine(r.index.Class(className), call)
+	} else if sub != nil {
+		r.checkCall(call, className, sub, callKind)
+	}
+}
+
+func (r *Resolver) reportUnknownSubroutine(class *Class, call *CallExpr) {
+	names := make([]string, 0)
+	for _, sub := range class.Subroutines {
+		names = append(names, sub.Name)
+	}
+	r.error(CODE_UNKNOWN_SUBROUTINE, nameRange(call.NamePos, call.Name), "class '"+class.Name+"' has no subroutine '"+call.Name+"'",
+		"not declared in '"+class.Name+"'", didYouMean(call.Name, names)...)
+}
+
+// Checks that the subroutine sub of class className can be called the way call does, with the right number of arguments
+func (r *Resolver) checkCall(call *CallExpr, className string, sub *Subroutine, callKind int) {
+	fullName := className + "." + sub.Name
+	rng := nameRange(call.NamePos, call.Name)
+	switch {
+	case sub.Kind == "method" && callKind == CALL_ON_CLASS:
+		{
+			r.error(CODE_METHOD_WITHOUT_OBJECT, rng, "method '"+fullName+"' is called on its class instead of an object", "needs an object",
+				"call it on a variable of type '"+className+"', or declare '"+sub.Name+"' as a function")
+		}
+	case sub.Kind == "method" && callKind == CALL_ON_THIS && r.sub.Kind == "function":
+		{
+			r.error(CODE_METHOD_WITHOUT_OBJECT, rng, "method '"+fullName+"' is called from function '"+r.sub.Name+"', which has no object", "needs an object",
+				"call it on a variable of type '"+className+"', or declare '"+r.sub.Name+"' as a method")
+		}
+	case sub.Kind != "method" && callKind == CALL_ON_OBJECT:
+		{
+			r.error(CODE_FUNCTION_ON_OBJECT, rng, sub.Kind+" '"+fullName+"' is called on an object", "not a method",
+				"call it on its class: "+fullName+"(...)")
+		}
+	}
+	if len(call.Args) != len(sub.Params) {
+		given := strconv.Itoa(len(call.Args)) + " were given"
+		if len(call.Args) == 1 {
+			given = "1 was given"
+		}
+		d := Diagnostic{
+			Severity: SEVERITY_ERROR,
+			Code:     CODE_ARGUMENT_COUNT,
+			Message:  "'" + fullName + "' takes " + plural(len(sub.Params), "argument") + " but " + given,
+			File:     rng.Start.File,
+			Range:    rng,
+			Label:    "expected " + plural(len(sub.Params), "argument"),
+		}
+		if sub.NamePos.File != OS_FILE {
+			d.Related = []RelatedLocation{{Message: "'" + fullName + "' is declared here", File: sub.NamePos.File, Range: nameRange(sub.NamePos, sub.Name)}}
+		}
+		r.reporter.Report(d)
+	}
+}
+
+// Looks a variable up in the subroutine scope first
+func (r *Resolver) lookup(name string) (Symbol, bool) {
+	return r.scope.Lookup(r.sub, name)
+}
+
+func (r *Resolver) isVariable(name string) bool {
+	_, ok := r.lookup(name)
+	return ok
+}
+
+// Returns the names of the variables that can be used in the subroutine
+func (r *Resolver) variableNames() []string {
+	names := make([]string, 0)
+	for _, symbol := range r.scope.SubroutineTable(r.sub).Symbols() {
+		names = append(names, symbol.Name)
+	}
+	for _, symbol := range r.scope.Table.Symbols() {
+		if symbol.Kind != FIELD || r.sub.Kind != "function" {
+			names = append(names, symbol.Name)
+		}
+	}
+	return names
+}
+
+func (r *Resolver) error(code string, rng Range, msg string, label string, hints ...string) {
+	r.reporter.Report(Diagnostic{Severity: SEVERITY_ERROR, Code: code, Message: msg, File: rng.Start.File, Range: rng, Label: label, Hints: hints})
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+func isPrimitiveType(sType string) bool {
+	return sType == "int" || sType == "char" || sType == "boolean"
+}
+
+// Returns the hint "did you mean 'candidate'?" for the candidate closest to a misspelled name,
+// or no hint if none is close enough
+func didYouMean(name string, candidates []string) []string {
+	best := ""
+	bestDistance := maxInt(1, len(name)/3) + 1 // the number of typos tolerated grows with the length of the name
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance < bestDistance && distance < len(name) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	return []string{"did you mean '" + best + "'?"}
+}
+
+// Returns the optimal string alignment distance of a and b: the number of characters to insert, delete or replace,
+// and of adjacent characters to swap, to turn a into b
+func editDistance(a string, b string) int {
+	prevPrev := make([]int, len(b)+1) // row i-2, for the swaps
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = minInt(cur[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, cur = prev, cur, prevPrev
+	}
+	return prev[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
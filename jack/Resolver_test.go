@@ -0,0 +1,80 @@
+package jack_test
+
+import (
+	"strings"
+	"testing"
+
+	"compiler/jack"
+)
+
+func TestDidYouMean(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      string // the hint, "" if there is none
+	}{
+		{"do Mian.main();", "did you mean 'Main'?"}, // swapped letters
+		{"do Man.main();", "did you mean 'Main'?"},
+		{"do Main.mian();", "did you mean 'main'?"},
+		{"do Main.mainn();", "did you mean 'main'?"},
+		{"do Main.walk();", ""},
+	}
+	for _, test := range tests {
+		diagnostics, _ := compileMain(t, test.statement+" return;", jack.Options{})
+		if len(diagnostics) != 1 {
+			t.Errorf("%s: got diagnostics %v, want one", test.statement, diagnostics)
+			continue
+		}
+		if got := strings.Join(diagnostics[0].Hints, " "); got != test.want {
+			t.Errorf("%s: got hints %q, want %q", test.statement, got, test.want)
+		}
+	}
+}
+
+// Each kind of name error is reported with its code
+func TestNameDiagnostics(t *testing.T) {
+	tests := []struct {
+		code    string
+		sources map[string]string
+	}{
+		{jack.CODE_UNDECLARED_VARIABLE, map[string]string{
+			"Main.jack": `class Main { function void main() { let x = 1; return; } }`}},
+		{jack.CODE_UNKNOWN_CLASS, map[string]string{
+			"Main.jack": `class Main { function void main() { var Game game; return; } }`}},
+		{jack.CODE_UNKNOWN_CLASS, map[string]string{
+			"Main.jack": `class Main { function void main() { do Game.run(); return; } }`}},
+		{jack.CODE_UNKNOWN_SUBROUTINE, map[string]string{
+			"Main.jack": `class Main { function void main() { do Output.printLine(); return; } }`}},
+		{jack.CODE_FIELD_IN_FUNCTION, map[string]string{
+			"Main.jack": `class Main { field int count; function void main() { let count = 1; return; } }`}},
+		{jack.CODE_NOT_AN_OBJECT, map[string]string{
+			"Main.jack": `class Main { function void main() { var int n; do n.run(); return; } }`}},
+		{jack.CODE_DUPLICATE_VARIABLE, map[string]string{
+			"Main.jack": `class Main { function void main() { var int n; var boolean n; return; } }`}},
+		{jack.CODE_DUPLICATE_VARIABLE, map[string]string{
+			"Main.jack": `class Main { static int n; field int n; function void main() { return; } }`}},
+		{jack.CODE_DUPLICATE_SUBROUTINE, map[string]string{
+			"Main.jack": `class Main { function void main() { return; } method void main() { return; } }`}},
+		{jack.CODE_DUPLICATE_CLASS, map[string]string{
+			"Main.jack":   `class Main { function void main() { return; } }`,
+			"Backup.jack": `class Main { function void main() { return; } }`}},
+		{jack.CODE_ARGUMENT_COUNT, map[string]string{
+			"Main.jack": `class Main { function void main() { do Output.printInt(1, 2); return; } }`}},
+		{jack.CODE_ARGUMENT_COUNT, map[string]string{
+			"Main.jack": `class Main { function void main() { do Game.run(); return; } }`,
+			"Game.jack": `class Game { function void run(int n) { return; } }`}},
+		{jack.CODE_METHOD_WITHOUT_OBJECT, map[string]string{
+			"Main.jack": `class Main { function void main() { do run(); return; } method void run() { return; } }`}},
+		{jack.CODE_METHOD_WITHOUT_OBJECT, map[string]string{
+			"Main.jack": `class Main { function void main() { do Game.run(); return; } }`,
+			"Game.jack": `class Game { method void run() { return; } }`}},
+		{jack.CODE_FUNCTION_ON_OBJECT, map[string]string{
+			"Main.jack": `class Main { function void main() { var Game game; do game.run(); return; } }`,
+			"Game.jack": `class Game { function void run() { return; } }`}},
+	}
+	for _, test := range tests {
+		_, diagnostics := compileSources(t, test.sources, jack.Options{})
+		if len(diagnostics) != 1 || diagnostics[0].Code != test.code {
+			t.Errorf("%v: got diagnostics %v, want one %s", test.sources, diagnostics, test.code)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package jack
+
+// A variable in a SymbolTable
+type Symbol struct {
+	Name  string
+	Type  string
+	Kind  string // STATIC, FIELD, ARG or VAR
+	Index int    // index of the variable in the segment of its kind
+	Pos   Position
+}
@@ -0,0 +1,206 @@
+package jack
+
+import "sort"
+
+const (
+	STATIC = "static"
+	FIELD  = "field"
+	ARG    = "argument"
+	VAR    = "var"
+	NONE   = "none"
+)
+
+type SymbolTable struct {
+	symbolMap   map[string]Symbol
+	staticIndex int
+	fieldIndex  int
+	argIndex    int
+	varIndex    int
+}
+
+func CreateSymbolTable() *SymbolTable {
+	t := &SymbolTable{}
+	return t
+}
+
+// Returns the table of the static and field variables of a class,
+// the variables declared twice are reported to reporter unless it is nil
+func ClassSymbolTable(class *Class, reporter *ErrorReporter) *SymbolTable {
+	t := CreateSymbolTable()
+	t.Reset()
+	for _, dec := range class.VarDecs {
+		for _, name := range dec.Names {
+			t.define(name.Name, dec.Type, dec.Kind, name.Pos, reporter)
+		}
+	}
+	return t
+}
+
+// Returns the table of the arguments and local variables of a subroutine,
+// the variables declared twice are reported to reporter unless it is nil
+func SubroutineSymbolTable(sub *Subroutine, reporter *ErrorReporter) *SymbolTable {
+	t := CreateSymbolTable()
+	t.Reset()
+	if sub.Kind == "method" {
+		t.argIndex++ // add "this" as argument for a method
+	}
+	for _, param := range sub.Params {
+		t.define(param.Name, param.Type, ARG, param.NamePos, reporter)
+	}
+	for _, dec := range sub.Locals {
+		for _, name := range dec.Names {
+			t.define(name.Name, dec.Type, VAR, name.Pos, reporter)
+		}
+	}
+	return t
+}
+
+func (t *SymbolTable) define(name string, sType string, kind string, pos Position, reporter *ErrorReporter) {
+	first, ok := t.Define(name, sType, kind, pos)
+	if ok || reporter == nil {
+		return
+	}
+	reporter.Redeclaration(CODE_DUPLICATE_VARIABLE, nameRange(pos, name), "variable '"+name+"' is declared twice", nameRange(first.Pos, name))
+}
+
+func (t *SymbolTable) Reset() {
+	t.symbolMap = make(map[string]Symbol)
+	t.staticIndex = 0
+	t.fieldIndex = 0
+	t.argIndex = 0
+	t.varIndex = 0
+}
+
+// Defines a variable declared at pos. If the name is already defined, the table keeps the first
+// definition, which is returned with ok false.
+func (t *SymbolTable) Define(name string, sType string, kind string, pos Position) (first Symbol, ok bool) {
+	if first, ok := t.symbolMap[name]; ok {
+		return first, false
+	}
+	symbol := Symbol{Name: name, Type: sType, Kind: kind, Pos: pos}
+	switch kind {
+	case STATIC:
+		{
+			symbol.Index = t.staticIndex
+			t.staticIndex++
+		}
+	case FIELD:
+		{
+			symbol.Index = t.fieldIndex
+			t.fieldIndex++
+		}
+	case ARG:
+		{
+			symbol.Index = t.argIndex
+			t.argIndex++
+		}
+	case VAR:
+		{
+			symbol.Index = t.varIndex
+			t.varIndex++
+		}
+	}
+	t.symbolMap[name] = symbol
+	return symbol, true
+}
+
+func (t *SymbolTable) VarCount(kind string) int {
+	res := 0
+	switch kind {
+	case STATIC:
+		{
+			res = t.staticIndex
+		}
+	case FIELD:
+		{
+			res = t.fieldIndex
+		}
+	case ARG:
+		{
+			res = t.argIndex
+		}
+	case VAR:
+		{
+			res = t.varIndex
+		}
+	}
+	return res
+}
+
+func (t *SymbolTable) KindOf(name string) string {
+	//fmt.Println("inside KindOf() name is " + name)
+	symbol, ok := t.symbolMap[name]
+	if !ok {
+		//fmt.Println("inside KindOf() none is " + name)
+		return NONE
+	}
+	return symbol.Kind
+}
+
+func (t *SymbolTable) TypeOf(name string) string {
+	return t.symbolMap[name].Type
+}
+
+func (t *SymbolTable) IndexOf(name string) int {
+	return t.symbolMap[name].Index
+}
+
+// Returns the symbol of a name, ok is false if the name isn't defined in the table
+func (t *SymbolTable) Lookup(name string) (symbol Symbol, ok bool) {
+	symbol, ok = t.symbolMap[name]
+	return symbol, ok
+}
+
+// Returns every symbol of the table, ordered by kind (STATIC, FIELD, ARG then VAR) and index
+func (t *SymbolTable) Symbols() []Symbol {
+	kindOrder := map[string]int{STATIC: 0, FIELD: 1, ARG: 2, VAR: 3}
+	symbols := make([]Symbol, 0, len(t.symbolMap))
+	for _, symbol := range t.symbolMap {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Kind != symbols[j].Kind {
+			return kindOrder[symbols[i].Kind] < kindOrder[symbols[j].Kind]
+		}
+		return symbols[i].Index < symbols[j].Index
+	})
+	return symbols
+}
+
+// The symbol tables of a class and of its subroutines, built once and shared by the passes over the class
+type ClassScope struct {
+	Class            *Class
+	Table            *SymbolTable // static and field variables
+	reporter         *ErrorReporter
+	subroutineTables map[*Subroutine]*SymbolTable
+}
+
+// Builds the table of the variables of a class, the variables declared twice in the class or in one of
+// its subroutines are reported to reporter unless it is nil
+func CreateClassScope(class *Class, reporter *ErrorReporter) *ClassScope {
+	cs := &ClassScope{Class: class, Table: ClassSymbolTable(class, reporter), reporter: reporter,
+		subroutineTables: make(map[*Subroutine]*SymbolTable)}
+	return cs
+}
+
+// Returns the table of the arguments and local variables of a subroutine of the class, built the first time
+func (cs *ClassScope) SubroutineTable(sub *Subroutine) *SymbolTable {
+	t := cs.subroutineTables[sub]
+	if t == nil {
+		t = SubroutineSymbolTable(sub, cs.reporter)
+		cs.subroutineTables[sub] = t
+	}
+	return t
+}
+
+// Returns the variable a name stands for in a subroutine of the class, looking in the subroutine scope first.
+// If the name isn't a variable, ok is false and the symbol is of kind NONE.
+func (cs *ClassScope) Lookup(sub *Subroutine, name string) (symbol Symbol, ok bool) {
+	if symbol, ok := cs.SubroutineTable(sub).Lookup(name); ok {
+		return symbol, true
+	}
+	if symbol, ok := cs.Table.Lookup(name); ok {
+		return symbol, true
+	}
+	return Symbol{Name: name, Kind: NONE}, false
+}
@@ -0,0 +1,317 @@
+package jack
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Type of the null constant, which can be assigned to any object
+const NULL_TYPE = "null"
+
+// Checks the types of a class whose names all resolve: assignments, operands, conditions, arguments and returned values.
+//
+// The types are int, char, boolean, String, Array and the classes. Jack is weakly typed: int and char are
+// interchangeable, an Array can hold any object, and the elements of an Array have no type, so they match any type.
+// In lenient mode the mismatches are warnings, as the official compiler accepts them, in strict mode they are errors.
+type TypeChecker struct {
+	index    *ClassIndex
+	reporter *ErrorReporter
+	strict   bool
+	class    *Class
+	sub      *Subroutine // subroutine being checked
+	scope    *ClassScope
+}
+
+func CreateTypeChecker(index *ClassIndex, reporter *ErrorReporter) *TypeChecker {
+	tc := &TypeChecker{index: index, reporter: reporter}
+	return tc
+}
+
+// Makes the type mismatches errors instead of warnings
+func (tc *TypeChecker) SetStrict() {
+	tc.strict = true
+}
+
+func (tc *TypeChecker) CheckClass(scope *ClassScope) {
+	tc.class, tc.scope = scope.Class, scope
+	for _, sub := range tc.class.Subroutines {
+		tc.sub = sub
+		tc.checkStatements(sub.Body)
+	}
+}
+
+func (tc *TypeChecker) checkStatements(statements []Statement) {
+	for _, stmt := range statements {
+		switch s := stmt.(type) {
+		case *LetStmt:
+			{
+				targetType := tc.lookup(s.Name).Type
+				if s.Index != nil {
+					tc.expectNumber(s.Index, "an array index")
+					targetType = "" // the elements of an array have no type
+				}
+				valueType := tc.typeOf(s.Value)
+				if !assignable(targetType, valueType) {
+					tc.mismatch(CODE_INCOMPATIBLE_TYPES, exprRange(s.Value),
+						"can't assign "+describeType(valueType)+" to '"+s.Name+"' of type "+targetType, "expected "+targetType)
+				}
+			}
+		case *IfStmt:
+			{
+				tc.expectCondition(s.Cond, "if")
+				tc.checkStatements(s.Then)
+				tc.checkStatements(s.Else)
+			}
+		case *WhileStmt:
+			{
+				tc.expectCondition(s.Cond, "while")
+				tc.checkStatements(s.Body)
+			}
+		case *DoStmt:
+			{
+				tc.typeOf(s.Call)
+			}
+		case *ReturnStmt:
+			{
+				tc.checkReturn(s)
+			}
+		}
+	}
+}
+
+func (tc *TypeChecker) checkReturn(s *ReturnStmt) {
+	returnType := tc.sub.ReturnType
+	rng := Range{Start: s.Pos, End: s.Pos}
+	rng.End.Column += len("return")
+	rng.End.Offset += len("return")
+	switch {
+	case s.Value == nil && returnType != "void":
+		{
+			tc.mismatch(CODE_RETURN_TYPE, rng, "'"+tc.sub.Name+"' returns "+returnType+" but this return has no value", "expected a value of type "+returnType)
+		}
+	case s.Value != nil && returnType == "void":
+		{
+			tc.typeOf(s.Value)
+			tc.mismatch(CODE_RETURN_TYPE, exprRange(s.Value),
+				"'"+tc.sub.Name+"' is void but this return has a value", "not returned")
+		}
+	case s.Value != nil:
+		{
+			valueType := tc.typeOf(s.Value)
+			if !assignable(returnType, valueType) {
+				tc.mismatch(CODE_RETURN_TYPE, exprRange(s.Value),
+					"'"+tc.sub.Name+"' returns "+returnType+" but this returns "+describeType(valueType), "expected "+returnType)
+			}
+		}
+	}
+}
+
+// Returns the type of an expression, "" if it is unknown, after checking the types inside it
+func (tc *TypeChecker) typeOf(expr Expression) string {
+	switch e := expr.(type) {
+	case *IntLiteral:
+		{
+			return "int"
+		}
+	case *StringLiteral:
+		{
+			return "String"
+		}
+	case *CharLiteral:
+		{
+			return "char"
+		}
+	case *KeywordLiteral:
+		{
+			switch e.Keyword {
+			case "true", "false":
+				{
+					return "boolean"
+				}
+			case "null":
+				{
+					return NULL_TYPE
+				}
+			}
+			if tc.sub.Kind == "function" {
+				return ""
+			}
+			return tc.class.Name // this
+		}
+	case *VarRef:
+		{
+			return tc.lookup(e.Name).Type
+		}
+	case *IndexExpr:
+		{
+			tc.expectNumber(e.Index, "an array index")
+			return ""
+		}
+	case *CallExpr:
+		{
+			return tc.typeOfCall(e)
+		}
+	case *UnaryExpr:
+		{
+			operandType := tc.typeOf(e.Operand)
+			if e.Op == "-" || operandType != "boolean" {
+				tc.expectNumberType(operandType, exprRange(e.Operand), "the operand of '"+e.Op+"'")
+			}
+			if operandType == "boolean" {
+				return "boolean"
+			}
+			return "int"
+		}
+	case *BinaryExpr:
+		{
+			return tc.typeOfOperation(e)
+		}
+	case *ParenExpr:
+		{
+			return tc.typeOf(e.Inner)
+		}
+	}
+	return ""
+}
+
+func (tc *TypeChecker) typeOfOperation(e *BinaryExpr) string {
+	leftType := tc.typeOf(e.Left)
+	rightType := tc.typeOf(e.Right)
+	switch e.Op {
+	case "=":
+		{
+			if !assignable(leftType, rightType) && !assignable(rightType, leftType) {
+				tc.mismatch(CODE_OPERAND_TYPE, exprRange(e), "can't compare "+describeType(leftType)+" with "+describeType(rightType),
+					"different types")
+			}
+			return "boolean"
+		}
+	case "&", "|":
+		{
+			// logical operators on booleans, bitwise on numbers
+			if leftType == "boolean" || rightType == "boolean" {
+				if !assignable("boolean", leftType) {
+					tc.mismatch(CODE_OPERAND_TYPE, exprRange(e.Left),
+						"the operands of '"+e.Op+"' are "+describeType(leftType)+" and a boolean", "expected boolean")
+				}
+				if !assignable("boolean", rightType) {
+					tc.mismatch(CODE_OPERAND_TYPE, exprRange(e.Right),
+						"the operands of '"+e.Op+"' are a boolean and "+describeType(rightType), "expected boolean")
+				}
+				return "boolean"
+			}
+		}
+	case "<", ">":
+		{
+			tc.expectNumberType(leftType, exprRange(e.Left), "an operand of '"+e.Op+"'")
+			tc.expectNumberType(rightType, exprRange(e.Right), "an operand of '"+e.Op+"'")
+			return "boolean"
+		}
+	}
+	tc.expectNumberType(leftType, exprRange(e.Left), "an operand of '"+e.Op+"'")
+	tc.expectNumberType(rightType, exprRange(e.Right), "an operand of '"+e.Op+"'")
+	return "int"
+}
+
+// Checks the arguments of a call and returns the type it returns
+func (tc *TypeChecker) typeOfCall(call *CallExpr) string {
+	className, sub := tc.callTarget(call)
+	for i, arg := range call.Args {
+		argType := tc.typeOf(arg)
+		if sub == nil || i >= len(sub.Params) {
+			continue
+		}
+		param := sub.Params[i]
+		if !assignable(param.Type, argType) {
+			tc.mismatch(CODE_INCOMPATIBLE_TYPES, exprRange(arg),
+				"argument "+strconv.Itoa(i+1)+" of '"+className+"."+sub.Name+"' is "+describeType(argType)+", expected "+param.Type,
+				"expected "+param.Type+" for '"+param.Name+"'")
+		}
+	}
+	if sub == nil {
+		return ""
+	}
+	return sub.ReturnType
+}
+
+// Returns the subroutine a call resolves to and its class, nil if it isn't known
+func (tc *TypeChecker) callTarget(call *CallExpr) (string, *Subroutine) {
+	if call.Receiver == "" {
+		return tc.class.Name, tc.class.Subroutine(call.Name)
+	}
+	className := call.Receiver
+	if symbol := tc.lookup(call.Receiver); symbol.Kind != NONE {
+		className = symbol.Type
+	}
+	sub, _ := tc.index.Subroutine(className, call.Name)
+	return className, sub
+}
+
+func (tc *TypeChecker) expectCondition(cond Expression, statement string) {
+	if condType := tc.typeOf(cond); !assignable("boolean", condType) {
+		tc.mismatch(CODE_CONDITION_TYPE, exprRange(cond),
+			"the condition of '"+statement+"' is "+describeType(condType)+", not a boolean", "expected boolean")
+	}
+}
+
+func (tc *TypeChecker) expectNumber(expr Expression, what string) {
+	tc.expectNumberType(tc.typeOf(expr), exprRange(expr), what)
+}
+
+// Reports sType unless it is int or char, what describes the checked expression
+func (tc *TypeChecker) expectNumberType(sType string, rng Range, what string) {
+	if sType == "" || sType == "int" || sType == "char" {
+		return
+	}
+	tc.mismatch(CODE_OPERAND_TYPE, rng, what+" must be an int, found "+describeType(sType), "expected int")
+}
+
+// Reports a type mismatch, as an error in strict mode and a warning otherwise
+func (tc *TypeChecker) mismatch(code string, rng Range, msg string, label string) {
+	severity := SEVERITY_WARNING
+	if tc.strict {
+		severity = SEVERITY_ERROR
+	}
+	tc.reporter.Report(Diagnostic{Severity: severity, Code: code, Message: msg, File: rng.Start.File, Range: rng, Label: label})
+}
+
+// Returns the variable of the given name, of kind NONE if it isn't declared
+func (tc *TypeChecker) lookup(name string) Symbol {
+	symbol, _ := tc.scope.Lookup(tc.sub, name)
+	return symbol
+}
+
+// Tells whether a value of type valueType can be stored in a variable of type targetType, "" being an unknown type
+func assignable(targetType string, valueType string) bool {
+	switch {
+	case targetType == "" || valueType == "" || targetType == valueType:
+		{
+			return true
+		}
+	case (targetType == "int" || targetType == "char") && (valueType == "int" || valueType == "char"):
+		{
+			return true
+		}
+	case isPrimitiveType(targetType) || isPrimitiveType(valueType) || targetType == "void" || valueType == "void":
+		{
+			return false
+		}
+	}
+	// both are objects
+	return valueType == NULL_TYPE || targetType == "Array" || valueType == "Array"
+}
+
+// Returns the type with an article, for the messages
+func describeType(sType string) string {
+	switch {
+	case sType == NULL_TYPE || sType == "void":
+		{
+			return sType
+		}
+	case strings.ContainsRune("AEIOUaeiou", rune(sType[0])):
+		{
+			return "an " + sType
+		}
+	}
+	return "a " + sType
+}
@@ -0,0 +1,38 @@
+package jack_test
+
+import (
+	"testing"
+
+	"compiler/jack"
+)
+
+// The type mismatches underline the whole expression
+func TestTypeMismatchRanges(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      string // the text underlined by the mismatch, "" if there is none
+	}{
+		{"let b = n + 1 * 2;", "n + 1 * 2"},
+		{"if (n - Math.abs(-n)) {}", "n - Math.abs(-n)"},
+		{"let n = ~(n < 1) | b;", "~(n < 1) | b"},
+		{"let n = a[n];", ""}, // the elements of an array have no type
+		{"do Output.printString(n * 2);", "n * 2"},
+		{"let n = n + b;", "b"},
+	}
+	for _, test := range tests {
+		diagnostics, underlined := compileMain(t, "var int n; var boolean b; var Array a; "+test.statement+" return;", jack.Options{})
+		if test.want == "" {
+			if len(diagnostics) != 0 {
+				t.Errorf("%s: unexpected diagnostics %v", test.statement, diagnostics)
+			}
+			continue
+		}
+		if len(diagnostics) != 1 {
+			t.Errorf("%s: got diagnostics %v, want one", test.statement, diagnostics)
+			continue
+		}
+		if underlined[0] != test.want {
+			t.Errorf("%s: the diagnostic underlines %q, want %q", test.statement, underlined[0], test.want)
+		}
+	}
+}
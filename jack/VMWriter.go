@@ -1,7 +1,7 @@
-package main
+package jack
 
 import (
-	"os"
+	"io"
 	"strconv"
 )
 
@@ -29,56 +29,68 @@ const (
 	NOT = "not"
 )
 
-var labelIndex = 0
-
 type VMWriter struct {
-	outputFile *os.File
+	output     io.Writer
+	labelIndex int // the labels are numbered from 0 in each file
 }
 
-func CreateVMWriter(outputFile *os.File) *VMWriter {
-	vmw := &VMWriter{outputFile: outputFile}
+func CreateVMWriter(output io.Writer) *VMWriter {
+	vmw := &VMWriter{output: output}
 
 	return vmw
 }
 
 func (vmw *VMWriter) WritePush(segment string, index int) {
-	vmw.outputFile.WriteString("push " + segment + " " + strconv.Itoa(index) + "\n")
+	io.WriteString(vmw.output, "push "+segment+" "+strconv.Itoa(index)+"\n")
+}
+
+// Pushes any 16-bit word. The VM can't push constants above MAX_INT, so these
+// are pushed as the bitwise not of their complement.
+func (vmw *VMWriter) WritePushConstant(value int) {
+	if value > MAX_INT {
+		vmw.WritePush(CONSTANT, MAX_WORD-value)
+		vmw.WriteArithmetic(NOT)
+		return
+	}
+	vmw.WritePush(CONSTANT, value)
 }
 
 func (vmw *VMWriter) WritePop(segment string, index int) {
-	vmw.outputFile.WriteString("pop " + segment + " " + strconv.Itoa(index) + "\n")
+	io.WriteString(vmw.output, "pop "+segment+" "+strconv.Itoa(index)+"\n")
 }
 
 func (vmw *VMWriter) WriteArithmetic(command string) {
-	vmw.outputFile.WriteString(command + "\n")
+	io.WriteString(vmw.output, command+"\n")
 }
 
 func (vmw *VMWriter) WriteLabel(label string) {
-	vmw.outputFile.WriteString("label " + label + "\n")
+	io.WriteString(vmw.output, "label "+label+"\n")
 }
 
 func (vmw *VMWriter) WriteGoTo(label string) {
-	vmw.outputFile.WriteString("goto " + label + "\n")
+	io.WriteString(vmw.output, "goto "+label+"\n")
 }
 
 func (vmw *VMWriter) WriteIf(label string) {
-	vmw.outputFile.WriteString("if-goto " + label + "\n")
+	io.WriteString(vmw.output, "if-goto "+label+"\n")
 }
 
 func (vmw *VMWriter) WriteCall(name string, nArgs int) {
-	vmw.outputFile.WriteString("call " + name + " " + strconv.Itoa(nArgs) + "\n")
+	io.WriteString(vmw.output, "call "+name+" "+strconv.Itoa(nArgs)+"\n")
 }
 
 func (vmw *VMWriter) WriteFunction(name string, nArgs int) {
-	vmw.outputFile.WriteString("function " + name + " " + strconv.Itoa(nArgs) + "\n")
+	io.WriteString(vmw.output, "function "+name+" "+strconv.Itoa(nArgs)+"\n")
 }
 
 func (vmw *VMWriter) WriteReturn() {
-	vmw.outputFile.WriteString("return\n")
+	io.WriteString(vmw.output, "return\n")
 }
 
 func (vmw *VMWriter) Close() {
-	vmw.outputFile.Close()
+	if closer, ok := vmw.output.(io.Closer); ok {
+		closer.Close()
+	}
 }
 
 func (vmw *VMWriter) getSegmentOf(kind string) string {
@@ -107,7 +119,7 @@ func (vmw *VMWriter) getSegmentOf(kind string) string {
 }
 
 func (vmw *VMWriter) CreateLabel() string {
-	label := "LABEL_" + strconv.Itoa(labelIndex)
-	labelIndex++
+	label := "LABEL_" + strconv.Itoa(vmw.labelIndex)
+	vmw.labelIndex++
 	return label
 }
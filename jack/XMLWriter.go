@@ -0,0 +1,58 @@
+package jack
+
+import (
+	"io"
+	"strings"
+)
+
+// Escapes the characters that have a special meaning in XML
+var xmlEscaper = strings.NewReplacer("<", "&lt;", ">", "&gt;", "&", "&amp;", "\"", "&quot;")
+
+type XMLWriter struct {
+	output io.Writer
+	depth  int // number of currently open tags
+}
+
+func CreateXMLWriter(output io.Writer) *XMLWriter {
+	xw := &XMLWriter{output: output}
+	return xw
+}
+
+// Writes every token of the tokenizer in the course's XxxT.xml format, lexical errors are left in jt.Errors()
+func (xw *XMLWriter) WriteTokens(jt *JackTokenizer) {
+	io.WriteString(xw.output, "<tokens>\n")
+	for jt.HasMoreTokens() {
+		xw.WriteTerminal(jt.TokenType().String(), jt.CurrentToken())
+		jt.Advance()
+	}
+	io.WriteString(xw.output, "</tokens>\n")
+}
+
+// Writes a single token as <tokenType> value </tokenType>
+func (xw *XMLWriter) WriteTerminal(tokenType string, value string) {
+	xw.writeIndent()
+	io.WriteString(xw.output, "<"+tokenType+"> "+xmlEscaper.Replace(value)+" </"+tokenType+">\n")
+}
+
+// Opens a non-terminal of the parse tree, everything until the matching CloseTag is indented under it
+func (xw *XMLWriter) OpenTag(tag string) {
+	xw.writeIndent()
+	io.WriteString(xw.output, "<"+tag+">\n")
+	xw.depth++
+}
+
+func (xw *XMLWriter) CloseTag(tag string) {
+	xw.depth--
+	xw.writeIndent()
+	io.WriteString(xw.output, "</"+tag+">\n")
+}
+
+func (xw *XMLWriter) writeIndent() {
+	io.WriteString(xw.output, strings.Repeat("  ", xw.depth))
+}
+
+func (xw *XMLWriter) Close() {
+	if closer, ok := xw.output.(io.Closer); ok {
+		closer.Close()
+	}
+}